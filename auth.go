@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Bruno auth keys and the Postman auth keys they map to
+var (
+	ntlmKeys = [][2]string{
+		{"username", "username"},
+		{"password", "password"},
+		{"domain", "domain"},
+	}
+	awsv4Keys = [][2]string{
+		{"accessKeyId", "accessKey"},
+		{"secretAccessKey", "secretKey"},
+		{"sessionToken", "sessionToken"},
+		{"service", "service"},
+		{"region", "region"},
+	}
+	oauth2Keys = [][2]string{
+		{"access_token_url", "accessTokenUrl"},
+		{"authorization_url", "authUrl"},
+		{"callback_url", "redirect_uri"},
+		{"client_id", "clientId"},
+		{"client_secret", "clientSecret"},
+		{"scope", "scope"},
+		{"state", "state"},
+		{"username", "username"},
+		{"password", "password"},
+		{"refresh_token_url", "refreshTokenUrl"},
+		{"token_header_prefix", "headerPrefix"},
+	}
+)
+
+// isInheritAuth reports whether a parsed auth map defers to the parent folder or collection
+func isInheritAuth(auth map[string]string) bool {
+	if val, ok := auth["inherit"]; ok && val == "true" {
+		return true
+	}
+	return auth["mode"] == "inherit"
+}
+
+// buildPostmanAuth converts a resolved Bruno auth map into a Postman auth object.
+// The parser flattens "auth { mode: ... }" (or the request "auth:" key) and the
+// "auth:<mode> { ... }" block into one map. Modes with no Postman equivalent
+// are reported and exported without auth.
+func buildPostmanAuth(name string, auth map[string]string) *PostmanAuth {
+	if len(auth) == 0 {
+		return nil
+	}
+
+	mode := auth["mode"]
+	if mode == "" {
+		// Try to infer mode
+		if _, ok := auth["token"]; ok {
+			mode = "bearer"
+		} else if _, ok := auth["username"]; ok {
+			mode = "basic"
+		}
+	}
+
+	switch mode {
+	case "none":
+		return &PostmanAuth{Type: "noauth"}
+	case "bearer":
+		return &PostmanAuth{
+			Type:   "bearer",
+			Bearer: []AuthElement{authElement("token", auth["token"])},
+		}
+	case "basic":
+		return &PostmanAuth{
+			Type: "basic",
+			Basic: []AuthElement{
+				authElement("username", auth["username"]),
+				authElement("password", auth["password"]),
+			},
+		}
+	case "digest":
+		return &PostmanAuth{
+			Type: "digest",
+			Digest: []AuthElement{
+				authElement("username", auth["username"]),
+				authElement("password", auth["password"]),
+			},
+		}
+	case "ntlm":
+		return &PostmanAuth{
+			Type: "ntlm",
+			Ntlm: authElements(auth, ntlmKeys),
+		}
+	case "apikey":
+		in := "header"
+		if auth["placement"] == "queryparams" {
+			in = "query"
+		}
+		return &PostmanAuth{
+			Type: "apikey",
+			Apikey: []AuthElement{
+				authElement("key", auth["key"]),
+				authElement("value", auth["value"]),
+				authElement("in", in),
+			},
+		}
+	case "awsv4":
+		return &PostmanAuth{
+			Type:  "awsv4",
+			Awsv4: authElements(auth, awsv4Keys),
+		}
+	case "oauth2":
+		return buildOAuth2(auth)
+	default:
+		if mode != "" {
+			fmt.Printf("Warning: %s: auth mode '%s' has no Postman equivalent, exported without auth\n", name, mode)
+		}
+		return nil
+	}
+}
+
+// buildOAuth2 maps Bruno OAuth2 settings (all grant types) to Postman oauth2 elements
+func buildOAuth2(auth map[string]string) *PostmanAuth {
+	grantType := auth["grant_type"]
+	switch grantType {
+	case "password":
+		grantType = "password_credentials"
+	case "authorization_code":
+		if auth["pkce"] == "true" {
+			grantType = "authorization_code_with_pkce"
+		}
+	}
+
+	elements := []AuthElement{authElement("grant_type", grantType)}
+	elements = append(elements, authElements(auth, oauth2Keys)...)
+
+	switch auth["credentials_placement"] {
+	case "body":
+		elements = append(elements, authElement("client_authentication", "body"))
+	case "basic_auth_header":
+		elements = append(elements, authElement("client_authentication", "header"))
+	}
+	switch auth["token_placement"] {
+	case "header":
+		elements = append(elements, authElement("addTokenTo", "header"))
+	case "url":
+		elements = append(elements, authElement("addTokenTo", "queryParams"))
+	}
+
+	return &PostmanAuth{
+		Type:   "oauth2",
+		Oauth2: elements,
+	}
+}
+
+func authElement(key string, value string) AuthElement {
+	return AuthElement{
+		Key:   key,
+		Value: value,
+		Type:  "string",
+	}
+}
+
+// authElements maps Bruno auth keys to Postman auth keys, skipping empty values
+func authElements(auth map[string]string, keys [][2]string) []AuthElement {
+	elements := []AuthElement{}
+	for _, k := range keys {
+		if value := auth[k[0]]; value != "" {
+			elements = append(elements, authElement(k[1], value))
+		}
+	}
+	return elements
+}
+
+// bruAuthFromPostman converts a Postman auth object back into a Bruno auth map.
+// Types with no Bruno equivalent are reported and return nil.
+func bruAuthFromPostman(name string, pmAuth *PostmanAuth) map[string]string {
+	if pmAuth == nil {
+		return nil
+	}
+
+	auth := map[string]string{"mode": pmAuth.Type}
+	switch pmAuth.Type {
+	case "noauth":
+		auth["mode"] = "none"
+	case "bearer":
+		auth["token"] = authElementValues(pmAuth.Bearer)["token"]
+	case "basic":
+		values := authElementValues(pmAuth.Basic)
+		auth["username"] = values["username"]
+		auth["password"] = values["password"]
+	case "digest":
+		values := authElementValues(pmAuth.Digest)
+		auth["username"] = values["username"]
+		auth["password"] = values["password"]
+	case "ntlm":
+		reverseAuthElements(auth, pmAuth.Ntlm, ntlmKeys)
+	case "awsv4":
+		reverseAuthElements(auth, pmAuth.Awsv4, awsv4Keys)
+	case "apikey":
+		values := authElementValues(pmAuth.Apikey)
+		auth["key"] = values["key"]
+		auth["value"] = values["value"]
+		auth["placement"] = "header"
+		if values["in"] == "query" {
+			auth["placement"] = "queryparams"
+		}
+	case "oauth2":
+		values := authElementValues(pmAuth.Oauth2)
+		reverseAuthElements(auth, pmAuth.Oauth2, oauth2Keys)
+		switch values["grant_type"] {
+		case "password_credentials":
+			auth["grant_type"] = "password"
+		case "authorization_code_with_pkce":
+			auth["grant_type"] = "authorization_code"
+			auth["pkce"] = "true"
+		default:
+			auth["grant_type"] = values["grant_type"]
+		}
+		switch values["client_authentication"] {
+		case "header":
+			auth["credentials_placement"] = "basic_auth_header"
+		case "body":
+			auth["credentials_placement"] = "body"
+		}
+		switch values["addTokenTo"] {
+		case "header":
+			auth["token_placement"] = "header"
+		case "queryParams":
+			auth["token_placement"] = "url"
+		}
+	default:
+		fmt.Printf("Warning: %s: Postman auth type '%s' has no Bruno equivalent, imported without auth\n", name, pmAuth.Type)
+		return nil
+	}
+	return auth
+}
+
+func authElementValues(elements []AuthElement) map[string]string {
+	values := make(map[string]string)
+	for _, e := range elements {
+		values[e.Key] = e.Value
+	}
+	return values
+}
+
+// reverseAuthElements maps Postman auth keys back to Bruno auth keys
+func reverseAuthElements(auth map[string]string, elements []AuthElement, keys [][2]string) {
+	values := authElementValues(elements)
+	for _, k := range keys {
+		if value := values[k[1]]; value != "" {
+			auth[k[0]] = value
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+)
+
+func authValue(elements []AuthElement, key string) string {
+	for _, e := range elements {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+func TestBuildPostmanAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		auth     map[string]string
+		wantType string
+		check    func(t *testing.T, auth *PostmanAuth)
+	}{
+		{
+			name:     "none",
+			auth:     map[string]string{"mode": "none"},
+			wantType: "noauth",
+		},
+		{
+			name:     "apikey in query",
+			auth:     map[string]string{"mode": "apikey", "key": "X-Api-Key", "value": "{{apiKey}}", "placement": "queryparams"},
+			wantType: "apikey",
+			check: func(t *testing.T, auth *PostmanAuth) {
+				if authValue(auth.Apikey, "key") != "X-Api-Key" || authValue(auth.Apikey, "in") != "query" {
+					t.Errorf("Unexpected apikey elements: %+v", auth.Apikey)
+				}
+			},
+		},
+		{
+			name:     "digest",
+			auth:     map[string]string{"mode": "digest", "username": "admin", "password": "secret"},
+			wantType: "digest",
+			check: func(t *testing.T, auth *PostmanAuth) {
+				if authValue(auth.Digest, "username") != "admin" || authValue(auth.Digest, "password") != "secret" {
+					t.Errorf("Unexpected digest elements: %+v", auth.Digest)
+				}
+			},
+		},
+		{
+			name:     "ntlm",
+			auth:     map[string]string{"mode": "ntlm", "username": "admin", "password": "secret", "domain": "CORP"},
+			wantType: "ntlm",
+			check: func(t *testing.T, auth *PostmanAuth) {
+				if authValue(auth.Ntlm, "domain") != "CORP" {
+					t.Errorf("Unexpected ntlm elements: %+v", auth.Ntlm)
+				}
+			},
+		},
+		{
+			name:     "awsv4",
+			auth:     map[string]string{"mode": "awsv4", "accessKeyId": "AKIA", "secretAccessKey": "{{awsSecret}}", "service": "execute-api", "region": "us-east-1"},
+			wantType: "awsv4",
+			check: func(t *testing.T, auth *PostmanAuth) {
+				if authValue(auth.Awsv4, "accessKey") != "AKIA" || authValue(auth.Awsv4, "secretKey") != "{{awsSecret}}" || authValue(auth.Awsv4, "region") != "us-east-1" {
+					t.Errorf("Unexpected awsv4 elements: %+v", auth.Awsv4)
+				}
+			},
+		},
+		{
+			name:     "oauth2 password",
+			auth:     map[string]string{"mode": "oauth2", "grant_type": "password", "access_token_url": "https://auth.example.com/token", "username": "admin", "credentials_placement": "basic_auth_header"},
+			wantType: "oauth2",
+			check: func(t *testing.T, auth *PostmanAuth) {
+				if authValue(auth.Oauth2, "grant_type") != "password_credentials" || authValue(auth.Oauth2, "accessTokenUrl") != "https://auth.example.com/token" || authValue(auth.Oauth2, "client_authentication") != "header" {
+					t.Errorf("Unexpected oauth2 elements: %+v", auth.Oauth2)
+				}
+			},
+		},
+		{
+			name:     "oauth2 authorization code with pkce",
+			auth:     map[string]string{"mode": "oauth2", "grant_type": "authorization_code", "pkce": "true", "authorization_url": "https://auth.example.com/authorize", "callback_url": "https://app.example.com/cb"},
+			wantType: "oauth2",
+			check: func(t *testing.T, auth *PostmanAuth) {
+				if authValue(auth.Oauth2, "grant_type") != "authorization_code_with_pkce" || authValue(auth.Oauth2, "authUrl") != "https://auth.example.com/authorize" || authValue(auth.Oauth2, "redirect_uri") != "https://app.example.com/cb" {
+					t.Errorf("Unexpected oauth2 elements: %+v", auth.Oauth2)
+				}
+			},
+		},
+		{
+			name: "wsse is not supported",
+			auth: map[string]string{"mode": "wsse", "username": "admin", "password": "secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := buildPostmanAuth("Test Request", tt.auth)
+			if tt.wantType == "" {
+				if auth != nil {
+					t.Fatalf("Expected no auth, got %+v", auth)
+				}
+				return
+			}
+			if auth == nil || auth.Type != tt.wantType {
+				t.Fatalf("Expected auth type %s, got %+v", tt.wantType, auth)
+			}
+			if tt.check != nil {
+				tt.check(t, auth)
+			}
+		})
+	}
+}
@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type Config struct {
-	Folders     []string
+	Folders []string
+	// Replace overrides variables, Environment holds the variables of -env
 	Replace     map[string]string
+	Environment map[string]string
 	Remove      []string
 	Ignore      []string
 	Input       string
@@ -19,14 +23,54 @@ type Config struct {
 	Verbose     bool
 	KeepFolders bool
 	Title       string
+	// DropDisabled drops disabled (~) headers, query params and form fields
+	// instead of exporting them as disabled
+	DropDisabled bool
+	// Strict aborts the conversion on the first syntax error instead of
+	// skipping the malformed files and reporting them all at the end
+	Strict bool
+	// Endpoint selection, see excludeReason. Empty lists select everything.
+	IncludeTags    []string
+	ExcludeTags    []string
+	Methods        []string
+	Match          []Pattern
+	Exclude        []Pattern
+	ExcludeFolders []string
+	// InlineVars replaces {{variables}} with their values instead of leaving
+	// them to Postman collection variables
+	InlineVars bool
+	// Secrets holds the environment secrets whose values are withheld (left
+	// empty in Environment). InlineVars leaves them as {{name}}.
+	Secrets map[string]bool
+	// Variables resolves the variables in scope, set by WalkAndConvert and
+	// processFolder
+	Variables *VariableResolver
+	// ProcessEnv converts the {{process.env.NAME}} references, nil leaves them as they are
+	ProcessEnv *ProcessEnv
+	// InheritedHeaders holds the headers of collection.bru and the parent
+	// folder.bru files, set by WalkAndConvert and processFolder
+	InheritedHeaders []KeyValue
+}
+
+// variables returns the resolver of the variables in scope. Without one, only
+// the -replace and -env values are known.
+func (c Config) variables() *VariableResolver {
+	if c.Variables != nil {
+		return c.Variables
+	}
+	return NewVariableResolver(nil, c.Environment, c.Replace, c.Remove)
 }
 
 func isDisabledVariableKey(key string) bool {
 	return strings.HasPrefix(strings.TrimSpace(key), "~")
 }
 
-// WalkAndConvert walks the directory and converts .bru files to Postman collection
+// WalkAndConvert walks the directory and converts .bru files to Postman collection.
+// Malformed files are left out of the collection and returned as ParseErrors
+// along with it, or abort the conversion in strict mode.
 func WalkAndConvert(config Config) (*PostmanCollection, error) {
+	var problems ParseErrors
+
 	collectionName := "Bruno Collection"
 
 	if config.Title != "" {
@@ -58,48 +102,42 @@ func WalkAndConvert(config Config) (*PostmanCollection, error) {
 		Variable: []Variable{},
 	}
 
-	// Populate Collection Variables
-	removedVars := make(map[string]bool)
-	for _, r := range config.Remove {
-		removedVars[r] = true
-	}
-
-	existingVars := make(map[string]bool)
-	for k, v := range config.Replace {
-		if removedVars[k] || isDisabledVariableKey(k) {
-			continue
-		}
-		collection.Variable = append(collection.Variable, Variable{
-			Key:   k,
-			Value: v,
-		})
-		existingVars[k] = true
-	}
-
 	// Try to read collection.bru for global variables and auth
 	var globalAuth map[string]string
+	var collectionVars []KeyValue
 	collectionBruPath := filepath.Join(config.Input, "collection.bru")
 	if _, err := os.Stat(collectionBruPath); err == nil {
-		if bru, err := ParseBruFile(collectionBruPath); err == nil {
+		bru, err := parseCollectionFile(collectionBruPath, config, &problems)
+		if err != nil {
+			return nil, err
+		}
+		if bru != nil {
 			globalAuth = bru.Auth
-			for _, v := range bru.Vars {
-				if removedVars[v.Key] || isDisabledVariableKey(v.Key) {
-					continue
-				}
-				if !existingVars[v.Key] {
-					collection.Variable = append(collection.Variable, Variable{
-						Key:   v.Key,
-						Value: v.Value,
-					})
-					existingVars[v.Key] = true
-				}
-			}
+			collectionVars = preRequestVars(bru)
+			config.InheritedHeaders = bru.Headers
+		}
+	}
+
+	// Populate Collection Variables with the effective values at the root
+	environment := config.Environment
+	if config.ProcessEnv != nil {
+		collectionVars = mapKeyValues(collectionVars, config.ProcessEnv.replace)
+		environment = make(map[string]string)
+		for k, v := range config.Environment {
+			environment[k] = config.ProcessEnv.replace(v)
 		}
 	}
+	config.Variables = NewVariableResolver(collectionVars, environment, config.Replace, config.Remove)
+	for _, v := range config.Variables.Variables() {
+		collection.Variable = append(collection.Variable, Variable{
+			Key:   v.Key,
+			Value: v.Value,
+		})
+	}
 
 	// Helper function to process items
 	processItems := func(folderPath string, parentAuth map[string]string) ([]Item, error) {
-		item, err := processFolder(folderPath, config, parentAuth)
+		item, err := processFolder(folderPath, config, parentAuth, &problems)
 		if err != nil {
 			return nil, err
 		}
@@ -108,6 +146,9 @@ func WalkAndConvert(config Config) (*PostmanCollection, error) {
 				return []Item{*item}, nil
 			} else {
 				// Flatten: return the items inside the folder
+				if len(item.Event) > 0 || len(item.Variable) > 0 {
+					fmt.Printf("Warning: %s: folder scripts and variables are only exported with -keep-folders\n", item.Name)
+				}
 				return item.Item, nil
 			}
 		}
@@ -119,6 +160,9 @@ func WalkAndConvert(config Config) (*PostmanCollection, error) {
 		for _, folderName := range config.Folders {
 			folderPath := filepath.Join(config.Input, folderName)
 			items, err := processItems(folderPath, globalAuth)
+			if _, ok := err.(*ParseError); ok {
+				return nil, err
+			}
 			if err != nil {
 				fmt.Printf("Warning: Could not process folder '%s': %v\n", folderPath, err)
 				continue
@@ -132,21 +176,158 @@ func WalkAndConvert(config Config) (*PostmanCollection, error) {
 			fmt.Printf("Warning: Could not read input directory '%s': %v\n", config.Input, err)
 			return collection, nil
 		}
+		// Like in folders, the root folders come before the root requests
+		var folders, requests []seqItem
 		for _, entry := range entries {
-			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
-				items, err := processItems(filepath.Join(config.Input, entry.Name()), globalAuth)
+			// collection.bru holds the collection settings, not a request
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".bru") && entry.Name() != "collection.bru" {
+				request, err := processRequest(filepath.Join(config.Input, entry.Name()), config, globalAuth, &problems)
 				if err != nil {
 					return nil, err
 				}
-				collection.Item = append(collection.Item, items...)
+				if request != nil {
+					requests = append(requests, *request)
+				}
+				continue
+			}
+			// environments/ holds the environment files, not requests
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && entry.Name() != "environments" {
+				folderPath := filepath.Join(config.Input, entry.Name())
+				if excluded := excludedFolder(entry.Name(), config); excluded != "" {
+					if config.Verbose {
+						fmt.Printf("[SKIP] Skipped folder: %s (matches exclude folder '%s')\n", folderPath, excluded)
+					}
+					continue
+				}
+				folders = append(folders, seqItem{seq: folderSeq(folderPath), path: folderPath})
 			}
 		}
+		sortBySeq(folders)
+		for _, folder := range folders {
+			items, err := processItems(folder.path, globalAuth)
+			if err != nil {
+				return nil, err
+			}
+			collection.Item = append(collection.Item, items...)
+		}
+		sortBySeq(requests)
+		for _, request := range requests {
+			collection.Item = append(collection.Item, request.item)
+		}
 	}
 
+	// Variables of the process.env references rewritten during the conversion
+	if config.ProcessEnv != nil {
+		for _, v := range config.ProcessEnv.Variables() {
+			collection.Variable = append(collection.Variable, Variable{
+				Key:   v.Key,
+				Value: v.Value,
+			})
+		}
+		for _, name := range config.ProcessEnv.Missing() {
+			fmt.Printf("Warning: process.env.%s is not defined in the .env file or the environment\n", name)
+		}
+	}
+
+	if len(problems) > 0 {
+		return collection, problems
+	}
 	return collection, nil
 }
 
-func processFolder(path string, config Config, parentAuth map[string]string) (*Item, error) {
+// parseCollectionFile parses a .bru file of the collection. In strict mode a
+// malformed file returns its first syntax error, otherwise the errors are
+// added to problems and the file is skipped (nil BruFile, nil error).
+func parseCollectionFile(path string, config Config, problems *ParseErrors) (*BruFile, error) {
+	bru, err := ParseBruFile(path)
+	if errs, ok := err.(ParseErrors); ok {
+		if config.Strict {
+			return nil, errs[0]
+		}
+		*problems = append(*problems, errs...)
+		if config.Verbose {
+			fmt.Printf("[SKIP] Skipped: %s (syntax errors)\n", path)
+		}
+		return nil, nil
+	}
+	return bru, err
+}
+
+// seqItem is a folder or request with its Bruno seq, used to order a folder's children
+type seqItem struct {
+	seq  int
+	path string
+	item Item
+}
+
+// sortBySeq orders items like Bruno does: by seq, items without a seq last,
+// and ties in file name order
+func sortBySeq(items []seqItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].seq, items[j].seq
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+}
+
+// folderSeq returns the seq of a folder from its folder.bru, or 0
+func folderSeq(path string) int {
+	bru, err := ParseBruFile(filepath.Join(path, "folder.bru"))
+	if err != nil || bru == nil {
+		return 0
+	}
+	return bru.Seq
+}
+
+// applyFolderBru fills a folder item from its folder.bru: the docs become its
+// description, the vars its variables and the scripts its events
+func applyFolderBru(item *Item, bru *BruFile, config Config) {
+	replace := func(s string) string { return s }
+	if config.ProcessEnv != nil {
+		replace = config.ProcessEnv.replace
+		bru = config.ProcessEnv.rewriteScripts(bru)
+	}
+	item.Description = replace(bru.Docs)
+
+	removed := make(map[string]bool)
+	for _, r := range config.Remove {
+		removed[r] = true
+	}
+	for _, v := range preRequestVars(bru) {
+		if !v.Enabled || isDisabledVariableKey(v.Key) || removed[v.Key] {
+			continue
+		}
+		item.Variable = append(item.Variable, Variable{
+			Key:   v.Key,
+			Value: replace(v.Value),
+		})
+	}
+
+	item.Event = scriptEvents(bru)
+}
+
+// mergeHeaders merges the headers of a request or folder with the ones it
+// inherits. Like Bruno, an enabled header overrides the inherited header of
+// the same name; disabled inherited headers are dropped.
+func mergeHeaders(inherited []KeyValue, headers []KeyValue) []KeyValue {
+	overridden := make(map[string]bool)
+	for _, h := range headers {
+		if h.Enabled {
+			overridden[strings.ToLower(h.Key)] = true
+		}
+	}
+	var merged []KeyValue
+	for _, h := range inherited {
+		if h.Enabled && !overridden[strings.ToLower(h.Key)] {
+			merged = append(merged, h)
+		}
+	}
+	return append(merged, headers...)
+}
+
+func processFolder(path string, config Config, parentAuth map[string]string, problems *ParseErrors) (*Item, error) {
 	if config.Verbose {
 		fmt.Printf("Scanning folder: %s\n", path)
 	}
@@ -172,69 +353,56 @@ func processFolder(path string, config Config, parentAuth map[string]string) (*I
 	currentAuth := parentAuth
 	folderBruPath := filepath.Join(path, "folder.bru")
 	if _, err := os.Stat(folderBruPath); err == nil {
-		if bru, err := ParseBruFile(folderBruPath); err == nil {
+		bru, err := parseCollectionFile(folderBruPath, config, problems)
+		if err != nil {
+			return nil, err
+		}
+		if bru != nil {
 			// If folder has auth, check if it is inherit
-			if len(bru.Auth) > 0 {
-				isInherit := false
-				if val, ok := bru.Auth["inherit"]; ok && val == "true" {
-					isInherit = true
-				} else if mode, ok := bru.Auth["mode"]; ok && mode == "inherit" {
-					isInherit = true
-				}
-
-				if !isInherit {
-					currentAuth = bru.Auth
-				}
+			if len(bru.Auth) > 0 && !isInheritAuth(bru.Auth) {
+				currentAuth = bru.Auth
 			}
+			config.Variables = config.variables().With(ScopeFolder, preRequestVars(bru))
+			config.InheritedHeaders = mergeHeaders(config.InheritedHeaders, bru.Headers)
+			applyFolderBru(item, bru, config)
 		}
 	}
 
+	// Bruno lists sub-folders before requests, each ordered by seq
+	var folders, requests []seqItem
 	for _, entry := range entries {
 		fullPath := filepath.Join(path, entry.Name())
+		relPath, _ := filepath.Rel(config.Input, fullPath)
 		if entry.IsDir() {
-			subItem, err := processFolder(fullPath, config, currentAuth)
+			if excluded := excludedFolder(relPath, config); excluded != "" {
+				if config.Verbose {
+					fmt.Printf("[SKIP] Skipped folder: %s (matches exclude folder '%s')\n", fullPath, excluded)
+				}
+				continue
+			}
+			subItem, err := processFolder(fullPath, config, currentAuth, problems)
 			if err != nil {
 				return nil, err
 			}
 			if subItem != nil {
-				item.Item = append(item.Item, *subItem)
-			}
-		} else if strings.HasSuffix(entry.Name(), ".bru") {
-			// Ignore folder.bru files as they only contain metadata
-			if entry.Name() == "folder.bru" {
-				continue
+				folders = append(folders, seqItem{seq: folderSeq(fullPath), item: *subItem})
 			}
-
-			bru, err := ParseBruFile(fullPath)
+		} else if strings.HasSuffix(entry.Name(), ".bru") && entry.Name() != "folder.bru" {
+			// folder.bru files only contain the folder settings
+			request, err := processRequest(fullPath, config, currentAuth, problems)
 			if err != nil {
-				// Log error but maybe continue? For now return error
 				return nil, err
 			}
-
-			// Check ignore patterns
-			shouldIgnore := false
-			for _, pattern := range config.Ignore {
-				if strings.Contains(bru.Name, pattern) {
-					shouldIgnore = true
-					if config.Verbose {
-						fmt.Printf("[SKIP] Skipped: %s (matches ignore pattern '%s')\n", bru.Name, pattern)
-					}
-					break
-				}
-			}
-			if shouldIgnore {
-				continue
-			}
-
-			postmanItem := BruToPostman(bru, config, currentAuth)
-			if postmanItem != nil {
-				item.Item = append(item.Item, *postmanItem)
-				if config.Verbose {
-					fmt.Printf("[OK] Exported: %s\n", bru.Name)
-				}
+			if request != nil {
+				requests = append(requests, *request)
 			}
 		}
 	}
+	sortBySeq(folders)
+	sortBySeq(requests)
+	for _, child := range append(folders, requests...) {
+		item.Item = append(item.Item, child.item)
+	}
 
 	if len(item.Item) == 0 {
 		return nil, nil
@@ -243,7 +411,40 @@ func processFolder(path string, config Config, parentAuth map[string]string) (*I
 	return item, nil
 }
 
+// processRequest converts the request .bru file at path. It returns nil for
+// malformed files (in non-strict mode) and requests left out by the filters.
+func processRequest(path string, config Config, parentAuth map[string]string, problems *ParseErrors) (*seqItem, error) {
+	bru, err := parseCollectionFile(path, config, problems)
+	if err != nil || bru == nil {
+		return nil, err
+	}
+
+	// Check ignore patterns and filters
+	relPath, _ := filepath.Rel(config.Input, path)
+	if reason := excludeReason(bru, relPath, config); reason != "" {
+		if config.Verbose {
+			fmt.Printf("[SKIP] Skipped: %s (%s)\n", bru.Name, reason)
+		}
+		return nil, nil
+	}
+
+	postmanItem := BruToPostman(bru, config, parentAuth)
+	if postmanItem == nil {
+		return nil, nil
+	}
+	if config.Verbose {
+		fmt.Printf("[OK] Exported: %s\n", bru.Name)
+	}
+	return &seqItem{seq: bru.Seq, path: path, item: *postmanItem}, nil
+}
+
 func BruToPostman(bru *BruFile, config Config, parentAuth map[string]string) *Item {
+	if len(config.InheritedHeaders) > 0 {
+		merged := *bru
+		merged.Headers = mergeHeaders(config.InheritedHeaders, bru.Headers)
+		bru = &merged
+	}
+
 	// Apply replacements to URL and Body
 	url := bru.Url
 	body := bru.Body
@@ -251,12 +452,21 @@ func BruToPostman(bru *BruFile, config Config, parentAuth map[string]string) *It
 	// Check if the endpoint uses any removed variables
 	for _, r := range config.Remove {
 		placeholder := "{{" + r + "}}"
-		if strings.Contains(url, placeholder) || strings.Contains(body, placeholder) {
+		if strings.Contains(url, placeholder) || strings.Contains(body, placeholder) || strings.Contains(bru.GraphqlVars, placeholder) {
 			if config.Verbose {
 				fmt.Printf("[SKIP] Skipped: %s (uses removed variable '%s' in URL or Body)\n", bru.Name, r)
 			}
 			return nil
 		}
+		// Check form fields
+		for _, f := range append(bru.FormUrlEncoded, bru.MultipartForm...) {
+			if strings.Contains(f.Value, placeholder) {
+				if config.Verbose {
+					fmt.Printf("[SKIP] Skipped: %s (uses removed variable '%s' in Body)\n", bru.Name, r)
+				}
+				return nil
+			}
+		}
 		// Check Auth
 		for _, v := range bru.Auth {
 			if strings.Contains(v, placeholder) {
@@ -268,13 +478,52 @@ func BruToPostman(bru *BruFile, config Config, parentAuth map[string]string) *It
 		}
 	}
 
-	// We NO LONGER replace variables in the URL string.
-	// Instead, we rely on Postman Collection Variables.
-	// However, we might want to clean up the URL if it has issues, but generally {{var}} is fine.
+	if config.ProcessEnv != nil {
+		bru = mapBru(bru, config.ProcessEnv.replace)
+		parentAuth = mapAuth(parentAuth, config.ProcessEnv.replace)
+		bru = config.ProcessEnv.rewriteScripts(bru)
+		url, body = bru.Url, bru.Body
+	}
+
+	// Dynamic variables ({{$randomIPV4}}) get their Postman names
+	dynamic := newDynamicTranslator(BrunoToPostmanDynamic)
+	bru = mapBru(bru, dynamic.replace)
+	parentAuth = mapAuth(parentAuth, dynamic.replace)
+	url, body = bru.Url, bru.Body
+	dynamic.warnUnmapped(bru.Name, "Postman")
+
+	// Variables are left to Postman collection variables, unless they are
+	// inlined with -inline-vars
+	if config.InlineVars {
+		resolver := config.variables().ForRequest(bru)
+		in := newInliner(resolver.Values())
+		for name := range config.Secrets {
+			// A folder or request var of the same name isn't a secret
+			if _, scope, ok := resolver.Lookup(name); ok && scope == ScopeEnvironment {
+				in.keep[name] = true
+			}
+		}
+		if config.ProcessEnv != nil {
+			for _, v := range config.ProcessEnv.Variables() {
+				if config.ProcessEnv.isSecret(strings.TrimPrefix(v.Key, processEnvPrefix)) {
+					in.keep[v.Key] = true
+				} else {
+					in.values[v.Key] = v.Value
+				}
+			}
+		}
+		bru = mapBru(bru, in.replace)
+		parentAuth = mapAuth(parentAuth, in.replace)
+		url, body = bru.Url, bru.Body
+		defer in.warnUnresolved(bru.Name)
+	}
 
 	// Build Headers
 	headers := []Header{}
 	for _, h := range bru.Headers {
+		if !h.Enabled && config.DropDisabled {
+			continue
+		}
 		// Check removals
 		remove := false
 		for _, r := range config.Remove {
@@ -285,9 +534,10 @@ func BruToPostman(bru *BruFile, config Config, parentAuth map[string]string) *It
 		}
 		if !remove {
 			headers = append(headers, Header{
-				Key:   h.Key,
-				Value: h.Value,
-				Type:  "text",
+				Key:      h.Key,
+				Value:    h.Value,
+				Type:     "text",
+				Disabled: !h.Enabled,
 			})
 		}
 	}
@@ -305,93 +555,37 @@ func BruToPostman(bru *BruFile, config Config, parentAuth map[string]string) *It
 	// Handle Auth
 	// Logic: If bru.Auth is present and not "inherit", use it.
 	// If it is "inherit" or missing, use parentAuth.
-	var effectiveAuth map[string]string
-
-	// Check if explicit auth is set
-	if len(bru.Auth) > 0 {
-		// Check if it is explicitly set to inherit
-		if val, ok := bru.Auth["inherit"]; ok && val == "true" {
-			effectiveAuth = parentAuth
-		} else if _, ok := bru.Auth["awsv4"]; ok {
-			// TODO: Handle other auth types if needed, for now just use what's there
-			effectiveAuth = bru.Auth
-		} else if _, ok := bru.Auth["bearer"]; ok {
-			effectiveAuth = bru.Auth
-		} else if _, ok := bru.Auth["basic"]; ok {
-			effectiveAuth = bru.Auth
-		} else {
-			// Default fallback or custom logic
-			// If key "mode" exists (from our parser logic for 'auth { mode: ... }')
-			if mode, ok := bru.Auth["mode"]; ok {
-				if mode == "inherit" {
-					effectiveAuth = parentAuth
-				} else {
-					effectiveAuth = bru.Auth
-				}
-			} else {
-				// No mode, maybe just inherit?
-				// If empty, use parent?
-				// But len > 0.
-				// Let's assume if it's not inherit, it's specific.
-				effectiveAuth = bru.Auth
-			}
-		}
-	} else {
-		// No auth defined, inherit by default?
-		// Bruno defaults to inherit usually.
-		effectiveAuth = parentAuth
-	}
-
-	if len(effectiveAuth) > 0 {
-		// Construct Postman Auth
-		// We expect effectiveAuth to contain keys like "mode", "token", "username", "password"
-		// Our parser flattens "auth { mode: bearer }" and "auth:bearer { token: ... }" into one map.
-
-		mode := effectiveAuth["mode"]
-		if mode == "" {
-			// Try to infer mode
-			if _, ok := effectiveAuth["token"]; ok {
-				mode = "bearer"
-			} else if _, ok := effectiveAuth["username"]; ok {
-				mode = "basic"
-			}
-		}
-
-		if mode == "bearer" {
-			req.Auth = &PostmanAuth{
-				Type: "bearer",
-				Bearer: []AuthElement{
-					{
-						Key:   "token",
-						Value: effectiveAuth["token"],
-						Type:  "string",
-					},
-				},
-			}
-		} else if mode == "basic" {
-			req.Auth = &PostmanAuth{
-				Type: "basic",
-				Basic: []AuthElement{
-					{
-						Key:   "username",
-						Value: effectiveAuth["username"],
-						Type:  "string",
-					},
-					{
-						Key:   "password",
-						Value: effectiveAuth["password"],
-						Type:  "string",
-					},
-				},
-			}
-		}
+	effectiveAuth := parentAuth
+	if len(bru.Auth) > 0 && !isInheritAuth(bru.Auth) {
+		effectiveAuth = bru.Auth
 	}
+	req.Auth = buildPostmanAuth(bru.Name, effectiveAuth)
 
 	// Parse URL components
 	req.Url = parseUrl(url)
+	applyParams(&req.Url, bru, config.DropDisabled)
 
 	// Handle Body
-	if body != "" {
+	switch {
+	case bru.BodyMode == "graphql" || (bru.BodyMode == "" && bru.Type == "graphql"):
+		req.Body = &Body{
+			Mode: "graphql",
+			GraphQL: &GraphQLBody{
+				Query:     body,
+				Variables: bru.GraphqlVars,
+			},
+		}
+	case bru.BodyMode == "formUrlEncoded" && len(bru.FormUrlEncoded) > 0:
+		req.Body = &Body{
+			Mode:       "urlencoded",
+			URLEncoded: buildFormParams(bru.FormUrlEncoded, config.DropDisabled),
+		}
+	case bru.BodyMode == "multipartForm" && len(bru.MultipartForm) > 0:
+		req.Body = &Body{
+			Mode:     "formdata",
+			FormData: buildFormParams(bru.MultipartForm, config.DropDisabled),
+		}
+	case body != "":
 		req.Body = &Body{
 			Mode: "raw", // Default to raw
 			Raw:  body,
@@ -412,11 +606,16 @@ func BruToPostman(bru *BruFile, config Config, parentAuth map[string]string) *It
 	}
 
 	// Protocol Profile Behavior
-	if bru.Method == "GET" && body != "" {
-		item.ProtocolProfileBehavior = &ProtocolProfileBehavior{
-			DisableBodyPruning: true,
-		}
+	behavior := settingsBehavior(bru)
+	if bru.Method == "GET" && req.Body != nil {
+		behavior.DisableBodyPruning = true
 	}
+	if behavior != (ProtocolProfileBehavior{}) {
+		item.ProtocolProfileBehavior = &behavior
+	}
+
+	// Scripts, tests and asserts
+	item.Event = scriptEvents(bru)
 
 	// Handle Examples (Responses)
 	for _, ex := range bru.Examples {
@@ -433,12 +632,38 @@ func BruToPostman(bru *BruFile, config Config, parentAuth map[string]string) *It
 			PostmanPreviewLanguage: "json", // Default to json
 			Body:                   ex.Response.Body,
 		}
+		for _, h := range ex.Request.Headers {
+			if !h.Enabled && config.DropDisabled {
+				continue
+			}
+			pmResponse.OriginalRequest.Header = append(pmResponse.OriginalRequest.Header, Header{
+				Key:      h.Key,
+				Value:    h.Value,
+				Disabled: !h.Enabled,
+			})
+		}
+		applyParams(&pmResponse.OriginalRequest.Url, &BruFile{Query: ex.Request.Query}, config.DropDisabled)
+		if ex.Request.Body != "" {
+			pmResponse.OriginalRequest.Body = &Body{
+				Mode: "raw",
+				Raw:  ex.Request.Body,
+			}
+			switch ex.Request.BodyMode {
+			case "json", "xml", "text":
+				pmResponse.OriginalRequest.Body.Options = map[string]interface{}{
+					"raw": map[string]string{
+						"language": ex.Request.BodyMode,
+					},
+				}
+			}
+		}
 
 		// Headers
 		for _, h := range ex.Response.Headers {
 			pmResponse.Header = append(pmResponse.Header, Header{
-				Key:   h.Key,
-				Value: h.Value,
+				Key:      h.Key,
+				Value:    h.Value,
+				Disabled: !h.Enabled,
 			})
 		}
 
@@ -448,6 +673,174 @@ func BruToPostman(bru *BruFile, config Config, parentAuth map[string]string) *It
 	return item
 }
 
+// scriptEvents translates the scripts, tests and asserts of a request or
+// folder to Postman events
+func scriptEvents(bru *BruFile) []Event {
+	var events []Event
+	if strings.TrimSpace(bru.PreRequestScript) != "" {
+		translated, untranslated := TranslateScript(bru.PreRequestScript)
+		warnUntranslated(bru.Name, "pre-request script", untranslated)
+		events = append(events, newScriptEvent("prerequest", scriptLines(translated)))
+	}
+
+	// Postman runs post-response scripts, tests and asserts from a single
+	// test event. Unless it only holds tests, each part starts with a marker
+	// comment so that import can split them again.
+	var sections []string
+	parts := make(map[string][]string)
+	if strings.TrimSpace(bru.PostResponseScript) != "" {
+		translated, untranslated := TranslateScript(bru.PostResponseScript)
+		warnUntranslated(bru.Name, "post-response script", untranslated)
+		sections = append(sections, testSectionPostResponse)
+		parts[testSectionPostResponse] = scriptLines(translated)
+	}
+	if strings.TrimSpace(bru.Tests) != "" {
+		translated, untranslated := TranslateTests(bru.Tests)
+		warnUntranslated(bru.Name, "tests", untranslated)
+		sections = append(sections, testSectionTests)
+		parts[testSectionTests] = scriptLines(translated)
+	}
+	if assertLines, unsupported := CompileAsserts(bru.Asserts); len(assertLines) > 0 || len(unsupported) > 0 {
+		warnUntranslated(bru.Name, "assert", unsupported)
+		if len(assertLines) > 0 {
+			sections = append(sections, testSectionAssert)
+			parts[testSectionAssert] = assertLines
+		}
+	}
+
+	var testExec []string
+	for _, section := range sections {
+		if len(testExec) > 0 {
+			testExec = append(testExec, "")
+		}
+		if len(sections) > 1 || section != testSectionTests {
+			testExec = append(testExec, testSectionMarker+section)
+		}
+		testExec = append(testExec, parts[section]...)
+	}
+	if len(testExec) > 0 {
+		events = append(events, newScriptEvent("test", testExec))
+	}
+	return events
+}
+
+// testSectionMarker starts a part of the Postman test event, followed by the
+// name of the Bruno block it comes from
+const testSectionMarker = "// bru-ship: "
+
+const (
+	testSectionPostResponse = "script:post-response"
+	testSectionTests        = "tests"
+	testSectionAssert       = "assert"
+)
+
+// settingsBehavior maps the settings block of a request to Postman's
+// protocolProfileBehavior. Settings without an equivalent are reported.
+func settingsBehavior(bru *BruFile) ProtocolProfileBehavior {
+	var behavior ProtocolProfileBehavior
+	for _, s := range bru.Settings {
+		if !s.Enabled {
+			continue
+		}
+		switch s.Key {
+		case "encodeUrl":
+			behavior.DisableUrlEncoding = s.Value == "false"
+		case "followRedirects":
+			if follow, err := strconv.ParseBool(s.Value); err == nil {
+				behavior.FollowRedirects = &follow
+			}
+		case "maxRedirects":
+			if max, err := strconv.Atoi(s.Value); err == nil {
+				behavior.MaxRedirects = &max
+			}
+		default:
+			if s.Value != "" && s.Value != "0" && s.Value != "false" {
+				fmt.Printf("Warning: %s: setting '%s' has no Postman equivalent\n", bru.Name, s.Key)
+			}
+		}
+	}
+	return behavior
+}
+
+func newScriptEvent(listen string, exec []string) Event {
+	return Event{
+		Listen: listen,
+		Script: Script{
+			Type: "text/javascript",
+			Exec: exec,
+		},
+	}
+}
+
+// warnUntranslated reports the script calls or asserts with no Postman equivalent
+func warnUntranslated(name string, block string, untranslated []string) {
+	for _, call := range untranslated {
+		fmt.Printf("Warning: %s: could not translate '%s' in %s\n", name, call, block)
+	}
+}
+
+// buildFormParams converts form body fields to Postman form params.
+// Multipart values written as @file(a.png|b.png) become file fields.
+func buildFormParams(fields []KeyValue, dropDisabled bool) []FormParam {
+	params := []FormParam{}
+	for _, f := range fields {
+		if !f.Enabled && dropDisabled {
+			continue
+		}
+		param := FormParam{
+			Key:      f.Key,
+			Value:    f.Value,
+			Type:     "text",
+			Disabled: !f.Enabled,
+		}
+		if strings.HasPrefix(f.Value, "@file(") && strings.HasSuffix(f.Value, ")") {
+			files := strings.Split(strings.TrimSuffix(strings.TrimPrefix(f.Value, "@file("), ")"), "|")
+			param.Value = ""
+			param.Type = "file"
+			if len(files) == 1 {
+				param.Src = files[0]
+			} else {
+				param.Src = files
+			}
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// applyParams fills query and path variables from the params:query and
+// params:path blocks. The params:query block wins over the query string
+// because it also keeps the disabled params.
+func applyParams(u *Url, bru *BruFile, dropDisabled bool) {
+	if len(bru.Query) > 0 {
+		u.Query = []Query{}
+		for _, q := range bru.Query {
+			if !q.Enabled && dropDisabled {
+				continue
+			}
+			u.Query = append(u.Query, Query{
+				Key:      q.Key,
+				Value:    q.Value,
+				Disabled: !q.Enabled,
+			})
+		}
+	}
+
+	pathValues := make(map[string]string)
+	for _, p := range bru.Params {
+		pathValues[p.Key] = p.Value
+	}
+	for _, segment := range u.Path {
+		if strings.HasPrefix(segment, ":") && len(segment) > 1 {
+			name := strings.TrimPrefix(segment, ":")
+			u.Variable = append(u.Variable, Variable{
+				Key:   name,
+				Value: pathValues[name],
+			})
+		}
+	}
+}
+
 func parseUrl(url string) Url {
 	u := Url{
 		Raw: url,
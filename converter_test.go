@@ -229,3 +229,394 @@ func TestWalkAndConvert_DisabledPrefixedVariablesAreFiltered(t *testing.T) {
 		t.Fatalf("expected baseUrl from collection.bru to be kept, got %q", got)
 	}
 }
+
+func TestBruToPostman_Params(t *testing.T) {
+	bru := &BruFile{
+		Name:   "Get User",
+		Method: "GET",
+		Url:    "{{baseUrl}}/users/:id?expand=profile",
+		Query: []KeyValue{
+			{Key: "expand", Value: "profile", Enabled: true},
+			{Key: "debug", Value: "true", Enabled: false},
+		},
+		Params: []KeyValue{
+			{Key: "id", Value: "42", Enabled: true},
+		},
+	}
+
+	item := BruToPostman(bru, Config{}, nil)
+	if item == nil {
+		t.Fatal("Expected item to NOT be nil")
+	}
+
+	query := item.Request.Url.Query
+	if len(query) != 2 {
+		t.Fatalf("Expected 2 query params, got %d", len(query))
+	}
+	if query[0].Disabled || !query[1].Disabled {
+		t.Errorf("Expected only the second query param to be disabled, got %+v", query)
+	}
+
+	variables := item.Request.Url.Variable
+	if len(variables) != 1 || variables[0].Key != "id" || variables[0].Value != "42" {
+		t.Errorf("Expected path variable id=42, got %+v", variables)
+	}
+}
+
+func TestBruToPostman_FormBodies(t *testing.T) {
+	bru := &BruFile{
+		Name:     "Token",
+		Method:   "POST",
+		Url:      "{{baseUrl}}/token",
+		BodyMode: "formUrlEncoded",
+		FormUrlEncoded: []KeyValue{
+			{Key: "grant_type", Value: "password", Enabled: true},
+			{Key: "scope", Value: "admin", Enabled: false},
+		},
+	}
+
+	item := BruToPostman(bru, Config{}, nil)
+	if item.Request.Body == nil || item.Request.Body.Mode != "urlencoded" {
+		t.Fatalf("Expected urlencoded body, got %+v", item.Request.Body)
+	}
+	if len(item.Request.Body.URLEncoded) != 2 || !item.Request.Body.URLEncoded[1].Disabled {
+		t.Errorf("Unexpected urlencoded params: %+v", item.Request.Body.URLEncoded)
+	}
+
+	bru = &BruFile{
+		Name:     "Upload",
+		Method:   "POST",
+		Url:      "{{baseUrl}}/upload",
+		BodyMode: "multipartForm",
+		MultipartForm: []KeyValue{
+			{Key: "title", Value: "Avatar", Enabled: true},
+			{Key: "file", Value: "@file(a.png)", Enabled: true},
+			{Key: "files", Value: "@file(a.png|b.png)", Enabled: true},
+		},
+	}
+
+	item = BruToPostman(bru, Config{}, nil)
+	if item.Request.Body == nil || item.Request.Body.Mode != "formdata" {
+		t.Fatalf("Expected formdata body, got %+v", item.Request.Body)
+	}
+	formData := item.Request.Body.FormData
+	if formData[0].Type != "text" || formData[0].Value != "Avatar" {
+		t.Errorf("Unexpected text field: %+v", formData[0])
+	}
+	if formData[1].Type != "file" || formData[1].Src != "a.png" {
+		t.Errorf("Unexpected file field: %+v", formData[1])
+	}
+	if src, ok := formData[2].Src.([]string); !ok || len(src) != 2 {
+		t.Errorf("Expected two file sources, got %+v", formData[2].Src)
+	}
+}
+
+func TestBruToPostman_GraphQL(t *testing.T) {
+	bru := &BruFile{
+		Name:        "Get Users",
+		Type:        "graphql",
+		Method:      "POST",
+		Url:         "{{baseUrl}}/graphql",
+		BodyMode:    "graphql",
+		Body:        "query { users { id } }",
+		GraphqlVars: `{"limit": 10}`,
+	}
+
+	item := BruToPostman(bru, Config{}, nil)
+	body := item.Request.Body
+	if body == nil || body.Mode != "graphql" || body.GraphQL == nil {
+		t.Fatalf("Expected graphql body, got %+v", body)
+	}
+	if body.GraphQL.Query != bru.Body {
+		t.Errorf("Expected query %q, got %q", bru.Body, body.GraphQL.Query)
+	}
+	if body.GraphQL.Variables != bru.GraphqlVars {
+		t.Errorf("Expected variables %q, got %q", bru.GraphqlVars, body.GraphQL.Variables)
+	}
+	if body.Raw != "" {
+		t.Errorf("Expected no raw body, got %q", body.Raw)
+	}
+}
+
+func TestBruToPostman_Scripts(t *testing.T) {
+	bru := &BruFile{
+		Name:               "Login",
+		Method:             "POST",
+		Url:                "{{baseUrl}}/login",
+		PreRequestScript:   "bru.setVar(\"ts\", Date.now());\n",
+		PostResponseScript: "bru.setEnvVar(\"token\", res.getBody().token);\n",
+		Tests:              "test(\"ok\", () => expect(res.status).to.eql(200));\n",
+		Asserts: []KeyValue{
+			{Key: "res.status", Value: "eq 200", Enabled: true},
+		},
+	}
+
+	item := BruToPostman(bru, Config{}, nil)
+	if len(item.Event) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(item.Event))
+	}
+	if item.Event[0].Listen != "prerequest" || item.Event[0].Script.Exec[0] != `pm.variables.set("ts", Date.now());` {
+		t.Errorf("Unexpected prerequest event: %+v", item.Event[0])
+	}
+	if item.Event[1].Listen != "test" || item.Event[1].Script.Exec[1] != `pm.environment.set("token", pm.response.json().token);` {
+		t.Errorf("Unexpected test event: %+v", item.Event[1])
+	}
+
+	exec := strings.Join(item.Event[1].Script.Exec, "\n")
+	if !strings.Contains(exec, `pm.test("ok", () => pm.expect(pm.response.code).to.eql(200));`) {
+		t.Errorf("Expected tests block in test event, got:\n%s", exec)
+	}
+	if !strings.Contains(exec, `pm.test("res.status: eq 200", function () {`) {
+		t.Errorf("Expected compiled assert in test event, got:\n%s", exec)
+	}
+	for _, marker := range []string{"// bru-ship: script:post-response", "// bru-ship: tests", "// bru-ship: assert"} {
+		if !strings.Contains(exec, marker) {
+			t.Errorf("Expected the %q marker in test event, got:\n%s", marker, exec)
+		}
+	}
+}
+
+func TestBruToPostman_DisabledEntries(t *testing.T) {
+	bru := &BruFile{
+		Name:   "Test Request",
+		Method: "GET",
+		Url:    "{{baseUrl}}/api/resource",
+		Headers: []KeyValue{
+			{Key: "Accept", Value: "application/json", Enabled: true},
+			{Key: "Authorization", Value: "Bearer old-token", Enabled: false},
+		},
+		Query: []KeyValue{
+			{Key: "debug", Value: "true", Enabled: false},
+		},
+	}
+
+	item := BruToPostman(bru, Config{}, nil)
+	if len(item.Request.Header) != 2 || item.Request.Header[0].Disabled || !item.Request.Header[1].Disabled {
+		t.Errorf("Expected only Authorization to be disabled, got %+v", item.Request.Header)
+	}
+	if len(item.Request.Url.Query) != 1 || !item.Request.Url.Query[0].Disabled {
+		t.Errorf("Expected disabled query param, got %+v", item.Request.Url.Query)
+	}
+
+	item = BruToPostman(bru, Config{DropDisabled: true}, nil)
+	if len(item.Request.Header) != 1 || item.Request.Header[0].Key != "Accept" {
+		t.Errorf("Expected disabled header to be dropped, got %+v", item.Request.Header)
+	}
+	if len(item.Request.Url.Query) != 0 {
+		t.Errorf("Expected disabled query param to be dropped, got %+v", item.Request.Url.Query)
+	}
+}
+
+func TestBruToPostman_AuthInheritance(t *testing.T) {
+	parentAuth := map[string]string{"mode": "basic", "username": "admin", "password": "secret"}
+
+	bru := &BruFile{
+		Name:   "Inherited",
+		Method: "GET",
+		Url:    "{{baseUrl}}/users",
+		Auth:   map[string]string{"mode": "inherit"},
+	}
+	item := BruToPostman(bru, Config{}, parentAuth)
+	if item.Request.Auth == nil || item.Request.Auth.Type != "basic" {
+		t.Errorf("Expected inherited basic auth, got %+v", item.Request.Auth)
+	}
+
+	bru.Auth = map[string]string{"mode": "none"}
+	item = BruToPostman(bru, Config{}, parentAuth)
+	if item.Request.Auth == nil || item.Request.Auth.Type != "noauth" {
+		t.Errorf("Expected noauth, got %+v", item.Request.Auth)
+	}
+}
+
+func TestWalkAndConvert_SyntaxErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	usersDir := filepath.Join(tmpDir, "Users")
+	if err := os.MkdirAll(usersDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := "meta {\n  name: List\n}\n\nget {\n  url: {{baseUrl}}/users\n}\n"
+	broken := "meta {\n  name: Create\n}\n\npost {\n  url: {{baseUrl}}/users\n\nbody:json {\n  {}\n}\n}\n"
+	if err := os.WriteFile(filepath.Join(usersDir, "List.bru"), []byte(valid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Create.bru", "Update.bru"} {
+		if err := os.WriteFile(filepath.Join(usersDir, name), []byte(broken), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Default mode: malformed files are skipped and all problems are returned
+	collection, err := WalkAndConvert(Config{Input: tmpDir})
+	problems, ok := err.(ParseErrors)
+	if !ok || len(problems) != 4 {
+		t.Fatalf("expected 4 syntax errors, got %v", err)
+	}
+	if files := problems.Files(); len(files) != 2 {
+		t.Errorf("expected 2 skipped files, got %v", files)
+	}
+	if problems[0].Line != 5 || !strings.HasSuffix(problems[0].Path, "Create.bru") {
+		t.Errorf("unexpected first error: %v", problems[0])
+	}
+	if collection == nil || len(collection.Item) != 1 || collection.Item[0].Name != "List" {
+		t.Fatalf("expected only the valid request to be converted, got %+v", collection)
+	}
+
+	// Strict mode: the first error aborts the conversion
+	collection, err = WalkAndConvert(Config{Input: tmpDir, Strict: true})
+	if _, ok := err.(*ParseError); !ok || collection != nil {
+		t.Fatalf("expected the conversion to abort with a ParseError, got %v", err)
+	}
+}
+
+func TestWalkAndConvert_SeqOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"Zeta/folder.bru":        "meta {\n  name: Zeta\n  seq: 1\n}\n",
+		"Zeta/Inner/folder.bru":  "meta {\n  name: Inner\n  seq: 2\n}\n",
+		"Zeta/Inner/A.bru":       "meta {\n  name: A\n}\n\nget {\n  url: /a\n}\n",
+		"Zeta/Late.bru":          "meta {\n  name: Late\n  seq: 3\n}\n\nget {\n  url: /late\n}\n",
+		"Zeta/Early.bru":         "meta {\n  name: Early\n  seq: 1\n}\n\nget {\n  url: /early\n}\n",
+		"Zeta/NoSeq.bru":         "meta {\n  name: NoSeq\n}\n\nget {\n  url: /none\n}\n",
+		"Alpha/folder.bru":       "meta {\n  name: Alpha\n  seq: 2\n}\n",
+		"Alpha/B.bru":            "meta {\n  name: B\n}\n\nget {\n  url: /b\n}\n",
+		"environments/Local.bru": "vars {\n  baseUrl: http://localhost\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collection, err := WalkAndConvert(Config{Input: tmpDir, KeepFolders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	var walk func(items []Item, prefix string)
+	walk = func(items []Item, prefix string) {
+		for _, item := range items {
+			names = append(names, prefix+item.Name)
+			walk(item.Item, prefix+item.Name+"/")
+		}
+	}
+	walk(collection.Item, "")
+
+	expected := "Zeta Zeta/Inner Zeta/Inner/A Zeta/Early Zeta/Late Zeta/NoSeq Alpha Alpha/B"
+	if got := strings.Join(names, " "); got != expected {
+		t.Errorf("Expected order %q, got %q", expected, got)
+	}
+}
+
+func TestBruToPostman_Settings(t *testing.T) {
+	bru := &BruFile{
+		Name:   "Settings",
+		Method: "GET",
+		Url:    "https://api.example.com",
+		Settings: []KeyValue{
+			{Key: "encodeUrl", Value: "false", Enabled: true},
+			{Key: "followRedirects", Value: "false", Enabled: true},
+			{Key: "maxRedirects", Value: "3", Enabled: true},
+			{Key: "timeout", Value: "0", Enabled: true},
+		},
+	}
+
+	item := BruToPostman(bru, Config{}, nil)
+	behavior := item.ProtocolProfileBehavior
+	if behavior == nil {
+		t.Fatal("Expected a protocolProfileBehavior")
+	}
+	if !behavior.DisableUrlEncoding || behavior.DisableBodyPruning {
+		t.Errorf("Unexpected flags: %+v", behavior)
+	}
+	if behavior.FollowRedirects == nil || *behavior.FollowRedirects {
+		t.Errorf("Expected followRedirects false, got %v", behavior.FollowRedirects)
+	}
+	if behavior.MaxRedirects == nil || *behavior.MaxRedirects != 3 {
+		t.Errorf("Expected maxRedirects 3, got %v", behavior.MaxRedirects)
+	}
+
+	bru.Settings = []KeyValue{{Key: "encodeUrl", Value: "true", Enabled: true}}
+	if item := BruToPostman(bru, Config{}, nil); item.ProtocolProfileBehavior != nil {
+		t.Errorf("Expected no protocolProfileBehavior, got %+v", item.ProtocolProfileBehavior)
+	}
+}
+
+func TestWalkAndConvert_FolderBru(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"collection.bru": "headers {\n  X-Client: bru-ship\n  X-Trace: collection\n}\n",
+		"Users/folder.bru": `meta {
+  name: Users
+}
+
+headers {
+  X-Trace: users
+  Accept: application/json
+  ~X-Debug: 1
+}
+
+vars:pre-request {
+  resource: users
+}
+
+script:pre-request {
+  bru.setVar("startedAt", Date.now());
+}
+
+tests {
+  test("is ok", function() {
+    expect(res.getStatus()).to.equal(200);
+  });
+}
+
+docs {
+  User management endpoints.
+}
+`,
+		"Users/List.bru": "meta {\n  name: List\n}\n\nget {\n  url: {{baseUrl}}/{{resource}}\n}\n\nheaders {\n  accept: text/csv\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collection, err := WalkAndConvert(Config{Input: tmpDir, KeepFolders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.Item) != 1 {
+		t.Fatalf("Expected one folder, got %+v", collection.Item)
+	}
+	folder := collection.Item[0]
+
+	if folder.Description != "User management endpoints.\n" {
+		t.Errorf("Unexpected folder description: %q", folder.Description)
+	}
+	if len(folder.Variable) != 1 || folder.Variable[0].Key != "resource" || folder.Variable[0].Value != "users" {
+		t.Errorf("Unexpected folder variables: %+v", folder.Variable)
+	}
+	if len(folder.Event) != 2 || folder.Event[0].Listen != "prerequest" || folder.Event[1].Listen != "test" {
+		t.Errorf("Unexpected folder events: %+v", folder.Event)
+	}
+
+	// Inherited headers come first, the request overrides Accept
+	var headers []string
+	for _, h := range folder.Item[0].Request.Header {
+		headers = append(headers, h.Key+": "+h.Value)
+	}
+	expected := "X-Client: bru-ship, X-Trace: users, accept: text/csv"
+	if got := strings.Join(headers, ", "); got != expected {
+		t.Errorf("Expected headers %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ParseDotEnv parses a .env file (KEY=VALUE lines) as used by Bruno at the
+// collection root. Comments, blank lines, "export " prefixes and quoted
+// values are supported.
+func ParseDotEnv(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	vars := make(map[string]string)
+	scanner := newLineReader(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			quote := value[0]
+			value = value[1 : len(value)-1]
+			if quote == '"' {
+				value = strings.ReplaceAll(value, `\n`, "\n")
+				value = strings.ReplaceAll(value, `\"`, `"`)
+			}
+		} else if idx := strings.Index(value, " #"); idx != -1 {
+			// Inline comment on an unquoted value
+			value = strings.TrimSpace(value[:idx])
+		}
+		vars[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// loadDotEnv reads the .env file at the collection root, if any
+func loadDotEnv(input string) map[string]string {
+	vars, err := ParseDotEnv(filepath.Join(input, ".env"))
+	if err != nil {
+		return map[string]string{}
+	}
+	return vars
+}
+
+var processEnvReference = regexp.MustCompile(`\{\{\s*process\.env\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// resolveProcessEnv replaces {{process.env.NAME}} references with the values
+// found in dotenv. Unknown references are kept.
+func resolveProcessEnv(value string, dotenv map[string]string) string {
+	return processEnvReference.ReplaceAllStringFunc(value, func(ref string) string {
+		name := processEnvReference.FindStringSubmatch(ref)[1]
+		if v, ok := dotenv[name]; ok {
+			return v
+		}
+		return ref
+	})
+}
+
+// Modes of -process-env
+const (
+	ProcessEnvRewrite = "rewrite"
+	ProcessEnvResolve = "resolve"
+	ProcessEnvKeep    = "keep"
+)
+
+// processEnvPrefix names the Postman variables that {{process.env.NAME}}
+// references are rewritten to: {{process_env_NAME}}
+const processEnvPrefix = "process_env_"
+
+// secretEnvName matches the process.env names that usually hold secrets
+var secretEnvName = regexp.MustCompile(`(?i)token|secret|passw(or)?d|api[-_]?key|private[-_]?key|credential`)
+
+// scriptProcessEnv matches bru.getProcessEnv("NAME") calls in scripts
+var scriptProcessEnv = regexp.MustCompile("\\bbru\\.getProcessEnv\\(\\s*[\"'`](\\w+)[\"'`]\\s*\\)")
+
+// ProcessEnv converts the {{process.env.NAME}} references of a collection,
+// which mean nothing in Postman. In rewrite mode they become
+// {{process_env_NAME}} collection variables holding the value, in resolve mode
+// the value is written in place. Secret values are never written unless
+// IncludeSecrets is set: the reference is rewritten and its variable left empty.
+type ProcessEnv struct {
+	Mode           string
+	Values         map[string]string
+	IncludeSecrets bool
+	// Secrets lists the names used by vars:secret variables, on top of the
+	// names that look like secrets (API_KEY, DB_PASSWORD...)
+	Secrets map[string]bool
+	used    map[string]bool
+}
+
+// LoadProcessEnv returns the process.env values of a collection: the process
+// environment, overridden by the .env file at the collection root, overridden
+// by the dotenv file if given
+func LoadProcessEnv(input string, dotenv string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			values[key] = value
+		}
+	}
+	for k, v := range loadDotEnv(input) {
+		values[k] = v
+	}
+	if dotenv != "" {
+		vars, err := ParseDotEnv(dotenv)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range vars {
+			values[k] = v
+		}
+	}
+	return values, nil
+}
+
+// isSecret reports whether the value of a name must be kept out of the output
+func (p *ProcessEnv) isSecret(name string) bool {
+	return !p.IncludeSecrets && (p.Secrets[name] || secretEnvName.MatchString(name))
+}
+
+// replace converts the process.env references of s
+func (p *ProcessEnv) replace(s string) string {
+	if p.Mode == ProcessEnvKeep {
+		return s
+	}
+	return processEnvReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := processEnvReference.FindStringSubmatch(ref)[1]
+		if value, ok := p.Values[name]; ok && p.Mode == ProcessEnvResolve && !p.isSecret(name) {
+			return value
+		}
+		p.use(name)
+		return "{{" + processEnvPrefix + name + "}}"
+	})
+}
+
+// rewriteScripts returns a copy of a request whose bru.getProcessEnv() calls
+// read the process_env_ variables instead. In keep mode the request is
+// returned as it is.
+func (p *ProcessEnv) rewriteScripts(bru *BruFile) *BruFile {
+	if p.Mode == ProcessEnvKeep {
+		return bru
+	}
+	rewrite := func(script string) string {
+		for _, match := range scriptProcessEnv.FindAllStringSubmatch(script, -1) {
+			p.use(match[1])
+		}
+		return scriptProcessEnv.ReplaceAllString(script, `pm.variables.get("`+processEnvPrefix+`$1")`)
+	}
+	rewritten := *bru
+	rewritten.PreRequestScript = rewrite(bru.PreRequestScript)
+	rewritten.PostResponseScript = rewrite(bru.PostResponseScript)
+	rewritten.Tests = rewrite(bru.Tests)
+	return &rewritten
+}
+
+func (p *ProcessEnv) use(name string) {
+	if p.used == nil {
+		p.used = make(map[string]bool)
+	}
+	p.used[name] = true
+}
+
+// Variables returns the process_env_ variables of the references rewritten so far
+func (p *ProcessEnv) Variables() []KeyValue {
+	var names []string
+	for name := range p.used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var vars []KeyValue
+	for _, name := range names {
+		value := p.Values[name]
+		if p.isSecret(name) {
+			value = ""
+		}
+		vars = append(vars, KeyValue{Key: processEnvPrefix + name, Value: value, Enabled: true})
+	}
+	return vars
+}
+
+// Missing returns the names referenced that have no value
+func (p *ProcessEnv) Missing() []string {
+	var missing []string
+	for name := range p.used {
+		if _, ok := p.Values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
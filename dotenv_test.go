@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	content := `# Local secrets
+API_KEY=abc123
+export TOKEN="quoted value"
+SINGLE='single # not a comment'
+PLAIN=value # comment
+
+INVALID LINE
+`
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars, err := ParseDotEnv(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"API_KEY": "abc123",
+		"TOKEN":   "quoted value",
+		"SINGLE":  "single # not a comment",
+		"PLAIN":   "value",
+	}
+	if len(vars) != len(expected) {
+		t.Errorf("Expected %d vars, got %v", len(expected), vars)
+	}
+	for k, v := range expected {
+		if vars[k] != v {
+			t.Errorf("Expected %s=%q, got %q", k, v, vars[k])
+		}
+	}
+}
+
+func TestResolveProcessEnv(t *testing.T) {
+	dotenv := map[string]string{"API_KEY": "abc123"}
+
+	got := resolveProcessEnv("Bearer {{process.env.API_KEY}} {{process.env.MISSING}}", dotenv)
+	if got != "Bearer abc123 {{process.env.MISSING}}" {
+		t.Errorf("Unexpected resolved value: %q", got)
+	}
+}
+
+func TestProcessEnv(t *testing.T) {
+	values := map[string]string{"API_KEY": "abc123", "HOST": "api.example.com"}
+	input := "https://{{process.env.HOST}}/?key={{ process.env.API_KEY }}&v={{process.env.MISSING}}"
+
+	rewrite := &ProcessEnv{Mode: ProcessEnvRewrite, Values: values}
+	if got := rewrite.replace(input); got != "https://{{process_env_HOST}}/?key={{process_env_API_KEY}}&v={{process_env_MISSING}}" {
+		t.Errorf("Unexpected rewrite: %s", got)
+	}
+	if got := rewrite.Missing(); len(got) != 1 || got[0] != "MISSING" {
+		t.Errorf("Expected MISSING to be reported, got %v", got)
+	}
+
+	resolve := &ProcessEnv{Mode: ProcessEnvResolve, Values: values}
+	if got := resolve.replace(input); got != "https://api.example.com/?key={{process_env_API_KEY}}&v={{process_env_MISSING}}" {
+		t.Errorf("Unexpected resolution: %s", got)
+	}
+	scripted := resolve.rewriteScripts(&BruFile{PreRequestScript: `req.setHeader("X-Host", bru.getProcessEnv("HOST"));`})
+	if scripted.PreRequestScript != `req.setHeader("X-Host", pm.variables.get("process_env_HOST"));` {
+		t.Errorf("Unexpected script rewrite: %s", scripted.PreRequestScript)
+	}
+	var got []string
+	for _, v := range resolve.Variables() {
+		got = append(got, v.Key+"="+v.Value)
+	}
+	if strings.Join(got, ",") != "process_env_API_KEY=,process_env_HOST=api.example.com,process_env_MISSING=" {
+		t.Errorf("Unexpected variables: %v", got)
+	}
+
+	include := &ProcessEnv{Mode: ProcessEnvResolve, Values: values, IncludeSecrets: true}
+	if got := include.replace(input); got != "https://api.example.com/?key=abc123&v={{process_env_MISSING}}" {
+		t.Errorf("Expected -include-env-secrets to resolve the secret, got %s", got)
+	}
+
+	keep := &ProcessEnv{Mode: ProcessEnvKeep, Values: values}
+	if got := keep.replace(input); got != input {
+		t.Errorf("Expected the references to be kept, got %s", got)
+	}
+	script := `const key = bru.getProcessEnv("API_KEY");`
+	if got := keep.rewriteScripts(&BruFile{PreRequestScript: script}); got.PreRequestScript != script {
+		t.Errorf("Expected the script to be kept, got %s", got.PreRequestScript)
+	}
+	if got := keep.Variables(); len(got) != 0 {
+		t.Errorf("Expected no variables in keep mode, got %v", got)
+	}
+}
+
+func TestWalkAndConvert_ProcessEnv(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":                  "TOKEN=s3cr3t\nREGION=eu\nSIGNING_SALT=p3pp3r\n",
+		"environments/Prod.bru": "vars {\n  region: {{process.env.REGION}}\n}\nvars:secret [\n  SIGNING_SALT\n]\n",
+		"Users/List.bru":        "meta {\n  name: List\n}\n\nget {\n  url: https://{{region}}.example.com/users\n  auth: bearer\n}\n\nauth:bearer {\n  token: {{process.env.TOKEN}}\n}\n",
+		"Users/Create.bru":      "meta {\n  name: Create\n}\n\npost {\n  url: https://example.com/{{process.env.REGION}}\n}\n\nheaders {\n  X-Salt: {{process.env.SIGNING_SALT}}\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env, err := ParseEnvironment(filepath.Join(dir, "environments", "Prod.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := LoadProcessEnv(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := Config{
+		Input:       dir,
+		Environment: EnvironmentVars(env, nil, false),
+		ProcessEnv:  &ProcessEnv{Mode: ProcessEnvRewrite, Values: values, Secrets: secretProcessEnvNames(env)},
+	}
+	collection, err := WalkAndConvert(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	variables := make(map[string]string)
+	for _, v := range collection.Variable {
+		variables[v.Key] = v.Value
+	}
+	if variables["region"] != "{{process_env_REGION}}" || variables["process_env_REGION"] != "eu" {
+		t.Errorf("Expected REGION to be rewritten, got %v", collection.Variable)
+	}
+	for _, name := range []string{"process_env_TOKEN", "process_env_SIGNING_SALT"} {
+		if value, ok := variables[name]; !ok || value != "" {
+			t.Errorf("Expected %s to be exported empty, got %v", name, collection.Variable)
+		}
+	}
+
+	// By default no secret value is ever written, anywhere in the collection
+	content, _ := json.Marshal(collection)
+	for _, unexpected := range []string{"process.env", "s3cr3t", "p3pp3r"} {
+		if strings.Contains(string(content), unexpected) {
+			t.Errorf("Unexpected %q in %s", unexpected, content)
+		}
+	}
+
+	config.ProcessEnv = &ProcessEnv{Mode: ProcessEnvRewrite, Values: values, IncludeSecrets: true}
+	collection, err = WalkAndConvert(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range collection.Variable {
+		if v.Key == "process_env_TOKEN" && v.Value != "s3cr3t" {
+			t.Errorf("Expected -include-env-secrets to export the TOKEN value, got %q", v.Value)
+		}
+	}
+}
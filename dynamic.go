@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dynamicReference matches a {{$name}} dynamic variable
+var dynamicReference = regexp.MustCompile(`\{\{\s*\$(\w+)\s*\}\}`)
+
+// postmanDynamicVariables are the dynamic variables Postman generates at run
+// time. Bruno supports the same faker-based set, plus the ones of
+// brunoDynamicVariables.
+var postmanDynamicVariables = []string{
+	"guid", "timestamp", "isoTimestamp", "randomUUID",
+	"randomAlphaNumeric", "randomBoolean", "randomInt", "randomColor", "randomHexColor", "randomAbbreviation",
+	"randomIP", "randomIPV6", "randomMACAddress", "randomPassword", "randomLocale", "randomUserAgent", "randomProtocol", "randomSemver",
+	"randomFirstName", "randomLastName", "randomFullName", "randomNamePrefix", "randomNameSuffix",
+	"randomJobArea", "randomJobDescriptor", "randomJobTitle", "randomJobType",
+	"randomPhoneNumber", "randomPhoneNumberExt",
+	"randomCity", "randomStreetName", "randomStreetAddress", "randomCountry", "randomCountryCode", "randomLatitude", "randomLongitude",
+	"randomAvatarImage", "randomImageUrl", "randomAbstractImage", "randomAnimalsImage", "randomBusinessImage", "randomCatsImage",
+	"randomCityImage", "randomFoodImage", "randomNightlifeImage", "randomFashionImage", "randomPeopleImage", "randomNatureImage",
+	"randomSportsImage", "randomTransportImage", "randomImageDataUri",
+	"randomBankAccount", "randomBankAccountName", "randomCreditCardMask", "randomBankAccountBic", "randomBankAccountIban",
+	"randomTransactionType", "randomCurrencyCode", "randomCurrencyName", "randomCurrencySymbol", "randomBitcoin",
+	"randomCompanyName", "randomCompanySuffix", "randomBs", "randomBsAdjective", "randomBsBuzz", "randomBsNoun",
+	"randomCatchPhrase", "randomCatchPhraseAdjective", "randomCatchPhraseDescriptor", "randomCatchPhraseNoun",
+	"randomDatabaseColumn", "randomDatabaseType", "randomDatabaseCollation", "randomDatabaseEngine",
+	"randomDateFuture", "randomDatePast", "randomDateRecent", "randomWeekday", "randomMonth",
+	"randomDomainName", "randomDomainSuffix", "randomDomainWord", "randomEmail", "randomExampleEmail", "randomUserName", "randomUrl",
+	"randomFileName", "randomFileType", "randomFileExt", "randomCommonFileName", "randomCommonFileType", "randomCommonFileExt",
+	"randomFilePath", "randomDirectoryPath", "randomMimeType",
+	"randomPrice", "randomProduct", "randomProductAdjective", "randomProductMaterial", "randomProductName", "randomDepartment",
+	"randomNoun", "randomVerb", "randomIngverb", "randomAdjective", "randomWord", "randomWords", "randomPhrase",
+	"randomLoremWord", "randomLoremWords", "randomLoremSentence", "randomLoremSentences", "randomLoremParagraph",
+	"randomLoremParagraphs", "randomLoremText", "randomLoremSlug", "randomLoremLines",
+}
+
+// brunoDynamicVariables are the Bruno-only dynamic variables, with their
+// Postman equivalent or "" when there is none
+var brunoDynamicVariables = map[string]string{
+	"randomIPV4":   "randomIP",
+	"randomNanoId": "",
+}
+
+var postmanDynamicSet = func() map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range postmanDynamicVariables {
+		set[name] = true
+	}
+	return set
+}()
+
+// BrunoToPostmanDynamic returns the Postman name of a Bruno dynamic variable
+// (without the $), false if Postman has no equivalent
+func BrunoToPostmanDynamic(name string) (string, bool) {
+	if postmanDynamicSet[name] {
+		return name, true
+	}
+	if postman := brunoDynamicVariables[name]; postman != "" {
+		return postman, true
+	}
+	return "", false
+}
+
+// PostmanToBrunoDynamic returns the Bruno name of a Postman dynamic variable
+// (without the $), false if Bruno has no equivalent. Bruno supports every
+// variable of postmanDynamicVariables under the same name, so the two tables
+// are the same list: only names unknown to Postman are rejected.
+func PostmanToBrunoDynamic(name string) (string, bool) {
+	if postmanDynamicSet[name] {
+		return name, true
+	}
+	return "", false
+}
+
+// dynamicTranslator renames the dynamic variables of a request with one of
+// the tables above, and records the ones it can't translate
+type dynamicTranslator struct {
+	translate func(string) (string, bool)
+	unmapped  map[string]bool
+}
+
+func newDynamicTranslator(translate func(string) (string, bool)) *dynamicTranslator {
+	return &dynamicTranslator{translate: translate, unmapped: make(map[string]bool)}
+}
+
+// replace renames the dynamic variables of s, untranslatable ones are kept
+func (d *dynamicTranslator) replace(s string) string {
+	return dynamicReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := dynamicReference.FindStringSubmatch(ref)[1]
+		translated, ok := d.translate(name)
+		if !ok {
+			d.unmapped[name] = true
+			return ref
+		}
+		return "{{$" + translated + "}}"
+	})
+}
+
+// warnUnmapped reports the dynamic variables with no equivalent in target
+func (d *dynamicTranslator) warnUnmapped(name string, target string) {
+	var refs []string
+	for variable := range d.unmapped {
+		refs = append(refs, "{{$"+variable+"}}")
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		fmt.Printf("Warning: %s: dynamic variable '%s' has no %s equivalent\n", name, ref, target)
+	}
+}
+
+// isDynamicVariable reports whether a variable name is a dynamic variable ($guid)
+func isDynamicVariable(name string) bool {
+	return strings.HasPrefix(name, "$")
+}
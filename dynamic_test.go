@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBrunoToPostmanDynamic(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{"guid", "guid", true},
+		{"randomFirstName", "randomFirstName", true},
+		{"randomIPV4", "randomIP", true},
+		{"randomNanoId", "", false},
+		{"notAFakerFunction", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := BrunoToPostmanDynamic(tt.name)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("%s: expected (%q, %v), got (%q, %v)", tt.name, tt.expected, tt.ok, got, ok)
+		}
+	}
+
+	if got, ok := PostmanToBrunoDynamic("randomIP"); got != "randomIP" || !ok {
+		t.Errorf("Expected randomIP to exist in Bruno, got (%q, %v)", got, ok)
+	}
+	if _, ok := PostmanToBrunoDynamic("randomIPV4"); ok {
+		t.Error("Expected randomIPV4 not to be a Postman dynamic variable")
+	}
+
+	// Bruno knows every Postman dynamic variable by the same name
+	for _, name := range postmanDynamicVariables {
+		if got, ok := PostmanToBrunoDynamic(name); got != name || !ok {
+			t.Errorf("%s: expected the same name in Bruno, got (%q, %v)", name, got, ok)
+		}
+		if got, ok := BrunoToPostmanDynamic(name); got != name || !ok {
+			t.Errorf("%s: expected the same name in Postman, got (%q, %v)", name, got, ok)
+		}
+	}
+}
+
+func TestBruToPostman_DynamicVariables(t *testing.T) {
+	bru := &BruFile{
+		Name:    "Create",
+		Method:  "POST",
+		Url:     "https://api.example.com/hosts/{{$randomIPV4}}",
+		Headers: []KeyValue{{Key: "X-Request-Id", Value: "{{ $guid }}", Enabled: true}},
+		Body:    `{"id": "{{$randomNanoId}}"}`,
+		Auth:    map[string]string{"mode": "bearer", "token": "{{$randomIPV4}}"},
+	}
+
+	item := BruToPostman(bru, Config{}, nil)
+	if item.Request.Url.Raw != "https://api.example.com/hosts/{{$randomIP}}" {
+		t.Errorf("Unexpected URL: %s", item.Request.Url.Raw)
+	}
+	if item.Request.Header[0].Value != "{{$guid}}" {
+		t.Errorf("Unexpected header: %s", item.Request.Header[0].Value)
+	}
+	if item.Request.Body.Raw != `{"id": "{{$randomNanoId}}"}` {
+		t.Errorf("Expected the unmappable variable to be kept, got %s", item.Request.Body.Raw)
+	}
+	if item.Request.Auth.Bearer[0].Value != "{{$randomIP}}" {
+		t.Errorf("Unexpected auth: %+v", item.Request.Auth.Bearer)
+	}
+
+	translator := newDynamicTranslator(BrunoToPostmanDynamic)
+	translator.replace(bru.Body + bru.Url)
+	if len(translator.unmapped) != 1 || !translator.unmapped["randomNanoId"] {
+		t.Errorf("Expected randomNanoId to be reported, got %v", translator.unmapped)
+	}
+}
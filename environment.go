@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// secretValue returns the value of a secret variable. Bruno keeps secret values
+// out of the environment file, so the .env file is checked first, then the
+// environment file value with its {{process.env.NAME}} references resolved.
+func secretValue(v KeyValue, dotenv map[string]string) string {
+	if value, ok := dotenv[v.Key]; ok {
+		return value
+	}
+	return resolveProcessEnv(v.Value, dotenv)
+}
+
+func secretNames(env *BruEnvironment) map[string]bool {
+	secrets := make(map[string]bool)
+	for _, s := range env.Secrets {
+		secrets[s.Key] = true
+	}
+	return secrets
+}
+
+// allVars returns the vars of an environment followed by the secrets listed
+// in vars:secret only, as Bruno writes them: their value is kept out of the
+// environment file
+func allVars(env *BruEnvironment) []KeyValue {
+	listed := make(map[string]bool)
+	vars := make([]KeyValue, 0, len(env.Vars)+len(env.Secrets))
+	for _, v := range env.Vars {
+		listed[v.Key] = true
+		vars = append(vars, v)
+	}
+	for _, s := range env.Secrets {
+		if !listed[s.Key] {
+			vars = append(vars, KeyValue{Key: s.Key, Enabled: s.Enabled})
+		}
+	}
+	return vars
+}
+
+// secretProcessEnvNames returns the process.env names that hold the secrets
+// of an environment: the names of its vars:secret entries, which secretValue
+// reads from .env, and the names referenced by their values
+func secretProcessEnvNames(env *BruEnvironment) map[string]bool {
+	secrets := secretNames(env)
+	names := secretNames(env)
+	for _, v := range env.Vars {
+		if secrets[v.Key] {
+			for _, match := range processEnvReference.FindAllStringSubmatch(v.Value, -1) {
+				names[match[1]] = true
+			}
+		}
+	}
+	return names
+}
+
+// EnvironmentVars returns the enabled variables of an environment, secrets
+// included. Secret values are left empty unless inlineSecrets is set.
+func EnvironmentVars(env *BruEnvironment, dotenv map[string]string, inlineSecrets bool) map[string]string {
+	secrets := secretNames(env)
+	vars := make(map[string]string)
+	for _, v := range allVars(env) {
+		if !v.Enabled {
+			continue
+		}
+		value := v.Value
+		if secrets[v.Key] {
+			value = ""
+			if inlineSecrets {
+				value = secretValue(v, dotenv)
+			}
+		}
+		vars[v.Key] = value
+	}
+	return vars
+}
+
+// BruEnvToPostman converts a Bruno environment to a Postman environment.
+// Secret variables are exported with the secret type and an empty value,
+// unless inlineSecrets is set.
+func BruEnvToPostman(env *BruEnvironment, dotenv map[string]string, inlineSecrets bool) *PostmanEnvironment {
+	secrets := secretNames(env)
+
+	pmEnv := &PostmanEnvironment{
+		Name:       env.Name,
+		Values:     []EnvironmentValue{},
+		Scope:      "environment",
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		ExportedBy: "bru-ship/" + version,
+	}
+	for _, v := range allVars(env) {
+		value := EnvironmentValue{
+			Key:     v.Key,
+			Value:   v.Value,
+			Type:    "default",
+			Enabled: v.Enabled,
+		}
+		if secrets[v.Key] {
+			value.Type = "secret"
+			value.Value = ""
+			if inlineSecrets {
+				value.Value = secretValue(v, dotenv)
+			}
+		}
+		pmEnv.Values = append(pmEnv.Values, value)
+	}
+	return pmEnv
+}
+
+// ExportEnvironments converts the environments/<name>.bru files of a collection
+// into <name>.postman_environment.json files in outputDir.
+// If names is empty every environment is exported.
+// It returns the paths of the written files.
+func ExportEnvironments(input string, names []string, outputDir string, inlineSecrets bool, verbose bool) ([]string, error) {
+	envDir := filepath.Join(input, "environments")
+	dotenv := loadDotEnv(input)
+
+	if len(names) == 0 {
+		entries, err := os.ReadDir(envDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".bru") {
+				names = append(names, strings.TrimSuffix(entry.Name(), ".bru"))
+			}
+		}
+	}
+
+	var written []string
+	for _, name := range names {
+		envPath := filepath.Join(envDir, name+".bru")
+		env, err := ParseEnvironment(envPath)
+		if err != nil {
+			return written, fmt.Errorf("could not load environment file %s: %v", envPath, err)
+		}
+
+		outputPath := filepath.Join(outputDir, name+".postman_environment.json")
+		if err := writeJSON(outputPath, BruEnvToPostman(env, dotenv, inlineSecrets)); err != nil {
+			return written, err
+		}
+		if verbose {
+			fmt.Printf("[OK] Exported environment: %s\n", name)
+		}
+		written = append(written, outputPath)
+	}
+	return written, nil
+}
+
+// writeJSON writes v as indented JSON, overwriting the file if it exists
+func writeJSON(path string, v interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const productionEnv = `vars {
+  baseUrl: https://api.example.com
+  ~debug: true
+}
+vars:secret [
+  apiKey,
+  ~password
+]
+`
+
+func TestParseEnvironment(t *testing.T) {
+	envPath := filepath.Join(t.TempDir(), "Production.bru")
+	if err := os.WriteFile(envPath, []byte(productionEnv), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := ParseEnvironment(envPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if env.Name != "Production" {
+		t.Errorf("Expected name Production, got %s", env.Name)
+	}
+	if len(env.Vars) != 2 || env.Vars[1].Key != "debug" || env.Vars[1].Enabled {
+		t.Errorf("Unexpected vars: %+v", env.Vars)
+	}
+	if len(env.Secrets) != 2 || env.Secrets[0].Key != "apiKey" || env.Secrets[1].Key != "password" || env.Secrets[1].Enabled {
+		t.Errorf("Unexpected secrets: %v", env.Secrets)
+	}
+
+	vars, err := ParseEnvFile(envPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := vars["debug"]; ok {
+		t.Errorf("Expected disabled variable to be left out of the map")
+	}
+}
+
+func TestExportEnvironments(t *testing.T) {
+	input := t.TempDir()
+	envDir := filepath.Join(input, "environments")
+	if err := os.MkdirAll(envDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(envDir, "Production.bru"), []byte(productionEnv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(envDir, "Local.bru"), []byte("vars {\n  baseUrl: http://localhost:8080\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	written, err := ExportEnvironments(input, nil, outputDir, false, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(written) != 2 {
+		t.Fatalf("Expected 2 environment files, got %v", written)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "Production.postman_environment.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var env PostmanEnvironment
+	if err := json.Unmarshal(content, &env); err != nil {
+		t.Fatal(err)
+	}
+
+	if env.Name != "Production" || env.Scope != "environment" {
+		t.Errorf("Unexpected environment header: %+v", env)
+	}
+	if len(env.Values) != 4 {
+		t.Errorf("Expected the vars and the secrets, got %+v", env.Values)
+	}
+	for _, v := range env.Values {
+		switch v.Key {
+		case "apiKey":
+			if v.Type != "secret" || v.Value != "" || !v.Enabled {
+				t.Errorf("Expected apiKey to be an empty secret, got %+v", v)
+			}
+		case "password":
+			if v.Type != "secret" || v.Enabled {
+				t.Errorf("Expected password to be a disabled secret, got %+v", v)
+			}
+		case "debug":
+			if v.Enabled {
+				t.Errorf("Expected debug to be disabled, got %+v", v)
+			}
+		case "baseUrl":
+			if v.Type != "default" || v.Value != "https://api.example.com" {
+				t.Errorf("Unexpected baseUrl: %+v", v)
+			}
+		}
+	}
+
+	written, err = ExportEnvironments(input, []string{"Local"}, t.TempDir(), false, false)
+	if err != nil || len(written) != 1 {
+		t.Errorf("Expected only the Local environment, got %v (%v)", written, err)
+	}
+}
+
+func TestEnvironmentSecrets(t *testing.T) {
+	env := &BruEnvironment{
+		Name: "Production",
+		Vars: []KeyValue{
+			{Key: "baseUrl", Value: "https://api.example.com", Enabled: true},
+		},
+		Secrets: []KeyValue{{Key: "apiKey", Enabled: true}, {Key: "password", Enabled: true}, {Key: "pin", Enabled: false}},
+	}
+	dotenv := map[string]string{"apiKey": "from-dotenv", "pin": "1234"}
+
+	vars := EnvironmentVars(env, dotenv, false)
+	if value, ok := vars["apiKey"]; !ok || value != "" {
+		t.Errorf("Expected apiKey to be defined and empty by default, got %v", vars)
+	}
+	if value, ok := vars["password"]; !ok || value != "" {
+		t.Errorf("Expected password to be defined and empty by default, got %v", vars)
+	}
+	if _, ok := vars["pin"]; ok {
+		t.Errorf("Expected the disabled secret to be left out, got %v", vars)
+	}
+	if vars["baseUrl"] != "https://api.example.com" {
+		t.Errorf("Expected baseUrl to be kept, got %v", vars)
+	}
+
+	vars = EnvironmentVars(env, dotenv, true)
+	if vars["apiKey"] != "from-dotenv" || vars["password"] != "" {
+		t.Errorf("Expected secrets to be inlined from .env, got %v", vars)
+	}
+
+	pmEnv := BruEnvToPostman(env, dotenv, true)
+	if len(pmEnv.Values) != 4 {
+		t.Fatalf("Expected every secret to be exported, got %+v", pmEnv.Values)
+	}
+	for _, v := range pmEnv.Values[1:] {
+		if v.Type != "secret" || v.Enabled != (v.Key != "pin") {
+			t.Errorf("Unexpected secret: %+v", v)
+		}
+	}
+	if pmEnv.Values[1].Value != "from-dotenv" {
+		t.Errorf("Expected inlined secret apiKey, got %+v", pmEnv.Values[1])
+	}
+}
+
+func TestParseEnvironment_LongValue(t *testing.T) {
+	token := strings.Repeat("t", 1<<20)
+	envPath := filepath.Join(t.TempDir(), "Local.bru")
+	if err := os.WriteFile(envPath, []byte("vars {\n  token: "+token+"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars, err := ParseEnvFile(envPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if vars["token"] != token {
+		t.Errorf("Expected the 1 MB value to be kept, got %d bytes", len(vars["token"]))
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// excludeReason returns why a request is left out of the export, or "" if it
+// is kept. relPath is the path of the .bru file relative to the collection root.
+func excludeReason(bru *BruFile, relPath string, config Config) string {
+	for _, pattern := range config.Ignore {
+		if strings.Contains(bru.Name, pattern) {
+			return fmt.Sprintf("matches ignore pattern '%s'", pattern)
+		}
+	}
+
+	if len(config.Methods) > 0 && !containsFold(config.Methods, bru.Method) {
+		return fmt.Sprintf("method %s is not in -methods", bru.Method)
+	}
+
+	if len(config.IncludeTags) > 0 {
+		included := false
+		for _, tag := range bru.Tags {
+			if contains(config.IncludeTags, tag) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return "has none of the -include-tags"
+		}
+	}
+	for _, tag := range bru.Tags {
+		if contains(config.ExcludeTags, tag) {
+			return fmt.Sprintf("has excluded tag '%s'", tag)
+		}
+	}
+
+	if len(config.Match) > 0 {
+		matched := false
+		for _, pattern := range config.Match {
+			if matchesRequest(pattern, bru, relPath) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "matches none of the -match patterns"
+		}
+	}
+	for _, pattern := range config.Exclude {
+		if matchesRequest(pattern, bru, relPath) {
+			return fmt.Sprintf("matches exclude pattern '%s'", pattern)
+		}
+	}
+	return ""
+}
+
+// excludedFolder returns the -exclude-folders entry matching a folder, or "".
+// Entries without a slash match a folder name at any depth, the others match
+// the folder's path relative to the collection root.
+func excludedFolder(relPath string, config Config) string {
+	relPath = filepath.ToSlash(relPath)
+	for _, folder := range config.ExcludeFolders {
+		folder = strings.Trim(filepath.ToSlash(folder), "/")
+		if strings.Contains(folder, "/") {
+			if relPath == folder {
+				return folder
+			}
+		} else if path.Base(relPath) == folder {
+			return folder
+		}
+	}
+	return ""
+}
+
+// Pattern is a compiled -match or -exclude pattern
+type Pattern struct {
+	Source string
+	re     *regexp.Regexp
+}
+
+func (p Pattern) String() string {
+	return p.Source
+}
+
+// matchesRequest reports whether a pattern matches the name of a request or
+// its relative file path
+func matchesRequest(pattern Pattern, bru *BruFile, relPath string) bool {
+	return pattern.re.MatchString(bru.Name) || pattern.re.MatchString(filepath.ToSlash(relPath))
+}
+
+// CompilePatterns compiles -match and -exclude patterns. A pattern between
+// slashes is a regular expression (/^Get .*/), anything else a glob matched
+// against the whole name or path: * and ? don't cross a slash, ** does.
+func CompilePatterns(patterns []string) ([]Pattern, error) {
+	var compiled []Pattern
+	for _, pattern := range patterns {
+		var expr string
+		if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			expr = pattern[1 : len(pattern)-1]
+		} else {
+			expr = globToRegexp(pattern)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %v", pattern, err)
+		}
+		compiled = append(compiled, Pattern{Source: pattern, re: re})
+	}
+	return compiled, nil
+}
+
+// globToRegexp converts a glob to an anchored regular expression
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExcludeReason(t *testing.T) {
+	mustCompile := func(patterns ...string) []Pattern {
+		compiled, err := CompilePatterns(patterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return compiled
+	}
+
+	login := &BruFile{Name: "Login", Method: "POST", Tags: []string{"smoke", "auth"}}
+	relPath := "Auth/Login.bru"
+
+	tests := []struct {
+		name   string
+		config Config
+		reason string
+	}{
+		{"no filters", Config{}, ""},
+		{"ignore", Config{Ignore: []string{"Log"}}, "matches ignore pattern 'Log'"},
+		{"method kept", Config{Methods: []string{"get", "post"}}, ""},
+		{"method", Config{Methods: []string{"GET"}}, "method POST is not in -methods"},
+		{"include tags kept", Config{IncludeTags: []string{"auth"}}, ""},
+		{"include tags", Config{IncludeTags: []string{"billing"}}, "has none of the -include-tags"},
+		{"exclude tags", Config{ExcludeTags: []string{"smoke"}}, "has excluded tag 'smoke'"},
+		{"glob on path", Config{Match: mustCompile("Auth/*")}, ""},
+		{"glob on name", Config{Match: mustCompile("Log?n")}, ""},
+		{"glob does not cross folders", Config{Match: mustCompile("*.bru")}, "matches none of the -match patterns"},
+		{"double star crosses folders", Config{Match: mustCompile("**.bru")}, ""},
+		{"regex", Config{Exclude: mustCompile("/^Log/")}, "matches exclude pattern '/^Log/'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := excludeReason(login, relPath, tt.config); got != tt.reason {
+				t.Errorf("Expected %q, got %q", tt.reason, got)
+			}
+		})
+	}
+
+	if _, err := CompilePatterns([]string{"/([/"}); err == nil {
+		t.Error("Expected an invalid regex to fail")
+	}
+}
+
+func TestWalkAndConvert_ExcludeFolders(t *testing.T) {
+	tmpDir := t.TempDir()
+	request := "meta {\n  name: %s\n}\n\nget {\n  url: /x\n}\n"
+	for _, name := range []string{"Users/List", "Users/Internal/Debug", "Internal/Metrics", "Orders/Legacy/Old", "Legacy/Kept"} {
+		path := filepath.Join(tmpDir, name+".bru")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := strings.Replace(request, "%s", filepath.Base(name), 1)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collection, err := WalkAndConvert(Config{Input: tmpDir, ExcludeFolders: []string{"Internal", "Orders/Legacy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range collection.Item {
+		names = append(names, item.Name)
+	}
+	if got := strings.Join(names, ","); got != "Kept,List" {
+		t.Errorf("Expected Kept,List, got %s", got)
+	}
+}
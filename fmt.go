@@ -0,0 +1,265 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runFmt implements "bru-ship fmt": rewrite the .bru files of a collection in canonical layout
+func runFmt(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	var input string
+	var check bool
+	var verbose bool
+	fs.StringVar(&input, "input", ".", "Root directory of Bruno collection, or a single .bru file")
+	fs.BoolVar(&check, "check", false, "Don't write files, print a diff of the unformatted ones and exit with status 1")
+	fs.BoolVar(&verbose, "verbose", false, "Enable verbose logging")
+	fs.Parse(args)
+
+	files, err := collectBruFiles(input)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", input, err)
+		return 1
+	}
+
+	unformatted := 0
+	failed := 0
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		formatted, err := FormatFile(path)
+		if err != nil {
+			fmt.Printf("Error formatting %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		if formatted == string(content) {
+			if verbose {
+				fmt.Printf("[OK] %s\n", path)
+			}
+			continue
+		}
+
+		unformatted++
+		if check {
+			fmt.Print(unifiedDiff(path, string(content), formatted))
+			continue
+		}
+		if err := os.WriteFile(path, []byte(formatted), 0644); err != nil {
+			fmt.Printf("Error writing %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Formatted: %s\n", path)
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	if check && unformatted > 0 {
+		fmt.Printf("%d of %d files are not formatted\n", unformatted, len(files))
+		return 1
+	}
+	return 0
+}
+
+// collectBruFiles lists the .bru files under root (or root itself if it is a file)
+func collectBruFiles(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".bru") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// isEnvironmentFile reports whether a .bru file is an environment (environments/<name>.bru)
+func isEnvironmentFile(path string) bool {
+	return filepath.Base(filepath.Dir(path)) == "environments"
+}
+
+// FormatFile returns the canonical layout of a .bru file. Files holding
+// blocks the parser doesn't read are rejected, rewriting them would drop
+// those blocks.
+func FormatFile(path string) (string, error) {
+	doc, err := parseBruPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	if isEnvironmentFile(path) {
+		for _, block := range doc.Blocks {
+			if block.Name != "vars" && block.Name != "vars:secret" {
+				return "", fmt.Errorf("unsupported block '%s'", block.Name)
+			}
+		}
+		return FormatEnvironment(environmentFromDocument("", doc)), nil
+	}
+
+	for _, block := range doc.Blocks {
+		if !isDataBlock(block.Name) && !isMethodBlock(block.Name) {
+			return "", fmt.Errorf("unsupported block '%s'", block.Name)
+		}
+		if block.Name == "example" {
+			if entry := unsupportedExampleEntry(block); entry != nil {
+				return "", fmt.Errorf("line %d: unsupported entry '%s' in example", entry.Line, entry.Key)
+			}
+		}
+	}
+	return FormatBruFile(bruFileFromDocument(doc)), nil
+}
+
+// exampleValues and exampleBlocks list the entries of the example blocks
+// that BruExample holds, by the name of the block they are in. The entries
+// of headers and params:query are free, "body:<mode>" entries of the request
+// are checked apart.
+var exampleValues = map[string][]string{
+	"example": {"name"},
+	"request": {"url", "method", "mode"},
+	"status":  {"code", "text"},
+	"body":    {"type", "content"},
+}
+
+var exampleBlocks = map[string][]string{
+	"example":  {"request", "response"},
+	"request":  {"headers", "params:query"},
+	"response": {"headers", "status", "body"},
+}
+
+// unsupportedExampleEntry returns the first entry of an example block that
+// BruExample doesn't hold, formatting the file would drop it
+func unsupportedExampleEntry(block *BruBlock) *BruEntry {
+	for i := range block.Entries {
+		e := &block.Entries[i]
+		switch {
+		case block.Name == "headers" || block.Name == "params:query":
+			if e.Block != nil {
+				return e
+			}
+		case e.Block == nil:
+			if !contains(exampleValues[block.Name], e.Key) || !e.Enabled {
+				return e
+			}
+		case block.Name == "request" && e.Block.Kind == TextBlock && strings.HasPrefix(e.Key, "body:"):
+		case !contains(exampleBlocks[block.Name], e.Key):
+			return e
+		default:
+			if nested := unsupportedExampleEntry(e.Block); nested != nil {
+				return nested
+			}
+		}
+	}
+	return nil
+}
+
+// unifiedDiff returns the differences between two texts in unified diff
+// format, with 3 lines of context
+func unifiedDiff(path string, a string, b string) string {
+	oldLines := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	newLines := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// Longest common subsequence table
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Edit script: ' ' keeps, '-' removes, '+' adds a line
+	type edit struct {
+		op   byte
+		line string
+		i, j int // line indexes in the old and new text before this edit
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			edits = append(edits, edit{' ', oldLines[i], i, j})
+			i++
+			j++
+		case i < len(oldLines) && (j == len(newLines) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', oldLines[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', newLines[j], i, j})
+			j++
+		}
+	}
+
+	const context = 3
+	var sb strings.Builder
+	sb.WriteString("--- " + path + "\n")
+	sb.WriteString("+++ " + path + " (formatted)\n")
+	for start := 0; start < len(edits); {
+		// Find the next change and the end of its hunk
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		from := max(first-context, start)
+		to := first
+		for k := first; k < len(edits); k++ {
+			if edits[k].op != ' ' {
+				to = k + 1
+			} else if k-to >= 2*context {
+				break
+			}
+		}
+		to = min(to+context, len(edits))
+
+		oldCount, newCount := 0, 0
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", edits[from].i+1, oldCount, edits[from].j+1, newCount)
+		for _, e := range edits[from:to] {
+			sb.WriteString(string(e.op) + e.line + "\n")
+		}
+		start = to
+	}
+	return sb.String()
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const unformattedBru = `meta {
+    name: Ping
+    type: http
+}
+headers {
+	X-Trace: 1
+}
+
+get {
+  url: {{baseUrl}}/ping
+  body: none
+  auth: none
+}
+`
+
+const formattedBru = `meta {
+  name: Ping
+  type: http
+}
+
+get {
+  url: {{baseUrl}}/ping
+  body: none
+  auth: none
+}
+
+headers {
+  X-Trace: 1
+}
+`
+
+func TestFormatFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Ping.bru")
+	if err := os.WriteFile(path, []byte(unformattedBru), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	formatted, err := FormatFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if formatted != formattedBru {
+		t.Errorf("Unexpected layout:\n%s", formatted)
+	}
+
+	envDir := filepath.Join(dir, "environments")
+	if err := os.MkdirAll(envDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	envPath := filepath.Join(envDir, "Local.bru")
+	if err := os.WriteFile(envPath, []byte("vars {\n    host: localhost\n}\nvars:secret [token, ~password]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	formatted, err = FormatFile(envPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if formatted != "vars {\n  host: localhost\n}\nvars:secret [\n  token,\n  ~password\n]\n" {
+		t.Errorf("Unexpected environment layout:\n%s", formatted)
+	}
+}
+
+// Formatting a canonical file must give it back unchanged, examples with
+// their request headers, params and body mode included
+func TestFormatFile_Idempotent(t *testing.T) {
+	fixtures, _ := filepath.Glob(filepath.Join("testdata", "*.bru"))
+	environments, _ := filepath.Glob(filepath.Join("testdata", "golden", "environments", "*.bru"))
+	for _, fixture := range append(fixtures, environments...) {
+		content, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		formatted, err := FormatFile(fixture)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", fixture, err)
+			continue
+		}
+		if formatted != string(content) {
+			t.Errorf("%s: formatting changed the file:\n%s", fixture, unifiedDiff(fixture, string(content), formatted))
+		}
+	}
+}
+
+func TestFormatFile_BodyBlocks(t *testing.T) {
+	// Bruno keeps the body of every mode used so far, not only the active one
+	files := map[string]string{
+		"Json.bru": `meta {
+  name: Json
+  type: http
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+}
+
+body:json {
+  {"a": 1}
+}
+
+body:text {
+  hello text
+}
+`,
+		"None.bru": `meta {
+  name: None
+  type: http
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: none
+  auth: none
+}
+
+body:xml {
+  <a>1</a>
+}
+`,
+	}
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		formatted, err := FormatFile(path)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if formatted != content {
+			t.Errorf("%s: formatting changed the file:\n%s", name, unifiedDiff(name, content, formatted))
+		}
+	}
+}
+
+func TestFormatFile_UnsupportedBlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Ping.bru")
+	content := formattedBru + "\nunknown {\n  x: 1\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FormatFile(path); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("Expected an unsupported block error, got %v", err)
+	}
+
+	// Example entries BruExample doesn't hold are refused too
+	content = formattedBru + "\nexample {\n  name: OK\n\n  request: {\n    url: /ping\n    method: get\n    params:path: {\n      id: 1\n    }\n  }\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FormatFile(path); err == nil || !strings.Contains(err.Error(), "params:path") {
+		t.Errorf("Expected an unsupported entry error, got %v", err)
+	}
+}
+
+func TestRunFmt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Ping.bru")
+	if err := os.WriteFile(path, []byte(unformattedBru), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Pong.bru"), []byte(formattedBru), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runFmt([]string{"-check", "-input", dir}); code != 1 {
+		t.Errorf("Expected -check to fail on unformatted files, got exit code %d", code)
+	}
+	if content, _ := os.ReadFile(path); string(content) != unformattedBru {
+		t.Errorf("Expected -check not to write files")
+	}
+
+	if code := runFmt([]string{"-input", dir}); code != 0 {
+		t.Errorf("Expected fmt to succeed, got exit code %d", code)
+	}
+	if content, _ := os.ReadFile(path); string(content) != formattedBru {
+		t.Errorf("Expected the file to be rewritten, got:\n%s", content)
+	}
+	if code := runFmt([]string{"-check", "-input", dir}); code != 0 {
+		t.Errorf("Expected -check to pass after formatting, got exit code %d", code)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	b := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+
+	expected := `--- x.bru
++++ x.bru (formatted)
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -9,3 +9,4 @@
+ i
+ j
+ k
++l
+`
+	if got := unifiedDiff("x.bru", a, b); got != expected {
+		t.Errorf("Unexpected diff:\n%s", got)
+	}
+}
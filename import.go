@@ -0,0 +1,460 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// runImport implements "bru-ship import": Postman collection or OpenAPI spec -> Bruno directory tree
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	var input string
+	var output string
+	var envFiles arrayFlags
+	var verbose bool
+	fs.StringVar(&input, "input", "", "Postman v2.1 collection JSON file, or OpenAPI 3.x / Swagger 2.0 spec (JSON or YAML)")
+	fs.StringVar(&output, "output", "", "Directory of the Bruno collection to create (default: collection name)")
+	fs.Var(&envFiles, "env-file", "Postman environment JSON file to import into environments/ (can be repeated)")
+	fs.BoolVar(&verbose, "verbose", false, "Enable verbose logging")
+	fs.Parse(args)
+
+	if input == "" {
+		fs.Usage()
+		return 1
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		fmt.Printf("Error reading collection: %v\n", err)
+		return 1
+	}
+
+	if spec, err := LoadOpenAPI(content); err == nil {
+		if output == "" {
+			output = sanitizeFileName(spec.Info.Title)
+		}
+		if err := ImportOpenAPI(spec, output, verbose); err != nil {
+			fmt.Printf("Error importing OpenAPI spec: %v\n", err)
+			return 1
+		}
+	} else if err != errNotOpenAPI {
+		fmt.Printf("Error parsing %s: %v\n", input, err)
+		return 1
+	} else {
+		var collection PostmanCollection
+		if err := json.Unmarshal(content, &collection); err != nil {
+			fmt.Printf("Error parsing collection: %v\n", err)
+			return 1
+		}
+
+		if output == "" {
+			output = sanitizeFileName(collection.Info.Name)
+		}
+
+		if err := ImportPostman(&collection, output, verbose); err != nil {
+			fmt.Printf("Error importing collection: %v\n", err)
+			return 1
+		}
+	}
+
+	for _, envFile := range envFiles {
+		content, err := os.ReadFile(envFile)
+		if err != nil {
+			fmt.Printf("Error reading environment: %v\n", err)
+			return 1
+		}
+		var pmEnv PostmanEnvironment
+		if err := json.Unmarshal(content, &pmEnv); err != nil {
+			fmt.Printf("Error parsing environment %s: %v\n", envFile, err)
+			return 1
+		}
+		env := PostmanEnvToBru(&pmEnv)
+		envDir := filepath.Join(output, "environments")
+		if err := os.MkdirAll(envDir, 0755); err != nil {
+			fmt.Printf("Error creating environments directory: %v\n", err)
+			return 1
+		}
+		envPath := filepath.Join(envDir, sanitizeFileName(env.Name)+".bru")
+		if err := os.WriteFile(envPath, []byte(FormatEnvironment(env)), 0644); err != nil {
+			fmt.Printf("Error writing environment: %v\n", err)
+			return 1
+		}
+		if verbose {
+			fmt.Printf("[OK] Imported environment: %s\n", env.Name)
+		}
+	}
+
+	absOutput, _ := filepath.Abs(output)
+	fmt.Printf("Import completed successfully! Bruno collection: %s\n", absOutput)
+	return 0
+}
+
+// ImportPostman writes a Postman collection as a Bruno collection directory:
+// bruno.json, collection.bru, one directory (with folder.bru) per folder and
+// one .bru file per request
+func ImportPostman(collection *PostmanCollection, outputDir string, verbose bool) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	brunoConfig := BrunoConfig{
+		Version: "1",
+		Name:    collection.Info.Name,
+		Type:    "collection",
+		Ignore:  []string{"node_modules", ".git"},
+	}
+	if err := writeJSON(filepath.Join(outputDir, "bruno.json"), brunoConfig); err != nil {
+		return err
+	}
+
+	collectionBru := &BruFile{
+		Auth: bruAuthFromPostman(collection.Info.Name, collection.Auth),
+		Docs: textBlock(collection.Info.Description),
+	}
+	for _, v := range collection.Variable {
+		collectionBru.Vars = append(collectionBru.Vars, KeyValue{Key: v.Key, Value: v.Value, Enabled: true})
+	}
+	applyPostmanEvents(collectionBru, collection.Info.Name, collection.Event)
+	if collectionBru.Auth == nil {
+		collectionBru.Auth = map[string]string{"mode": "none"}
+	}
+	if err := WriteBruFile(filepath.Join(outputDir, "collection.bru"), collectionBru); err != nil {
+		return err
+	}
+
+	return importItems(collection.Item, outputDir, verbose)
+}
+
+func importItems(items []Item, dir string, verbose bool) error {
+	usedNames := make(map[string]bool)
+	for i, item := range items {
+		name := uniqueFileName(sanitizeFileName(item.Name), usedNames)
+
+		if item.Request == nil {
+			// Folder
+			folderDir := filepath.Join(dir, name)
+			if err := os.MkdirAll(folderDir, 0755); err != nil {
+				return err
+			}
+			if err := WriteBruFile(filepath.Join(folderDir, "folder.bru"), postmanFolderToBru(item, i+1)); err != nil {
+				return err
+			}
+			if err := importItems(item.Item, folderDir, verbose); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := WriteBruFile(filepath.Join(dir, name+".bru"), PostmanItemToBru(item, i+1)); err != nil {
+			return err
+		}
+		if verbose {
+			fmt.Printf("[OK] Imported: %s\n", item.Name)
+		}
+	}
+	return nil
+}
+
+func postmanFolderToBru(item Item, seq int) *BruFile {
+	bru := &BruFile{
+		Name: item.Name,
+		Seq:  seq,
+		Auth: bruAuthFromPostman(item.Name, item.Auth),
+		Docs: textBlock(item.Description),
+	}
+	if bru.Auth == nil {
+		bru.Auth = map[string]string{"mode": "inherit"}
+	}
+	for _, v := range item.Variable {
+		bru.Vars = append(bru.Vars, KeyValue{Key: v.Key, Value: v.Value, Enabled: true})
+	}
+	applyPostmanEvents(bru, item.Name, item.Event)
+	return bru
+}
+
+// PostmanItemToBru converts a Postman request item into a BruFile
+func PostmanItemToBru(item Item, seq int) *BruFile {
+	req := item.Request
+	bru := &BruFile{
+		Name:     item.Name,
+		Type:     "http",
+		Seq:      seq,
+		Method:   strings.ToUpper(req.Method),
+		Url:      req.Url.Raw,
+		Headers:  []KeyValue{},
+		BodyMode: "none",
+		Auth:     bruAuthFromPostman(item.Name, req.Auth),
+		Docs:     textBlock(req.Description),
+	}
+	if bru.Auth == nil {
+		// Postman requests without auth inherit it from their parent
+		bru.Auth = map[string]string{"mode": "inherit"}
+	}
+
+	for _, q := range req.Url.Query {
+		bru.Query = append(bru.Query, KeyValue{Key: q.Key, Value: q.Value, Enabled: !q.Disabled})
+	}
+	for _, v := range req.Url.Variable {
+		bru.Params = append(bru.Params, KeyValue{Key: v.Key, Value: v.Value, Enabled: true})
+	}
+	for _, h := range req.Header {
+		bru.Headers = append(bru.Headers, KeyValue{Key: h.Key, Value: h.Value, Enabled: !h.Disabled})
+	}
+	bru.Settings = bruSettingsFromPostman(item.ProtocolProfileBehavior)
+
+	if req.Body != nil {
+		switch req.Body.Mode {
+		case "raw":
+			if req.Body.Raw != "" {
+				bru.BodyMode = rawBodyMode(req.Body)
+				bru.Body = textBlock(req.Body.Raw)
+			}
+		case "urlencoded":
+			bru.BodyMode = "formUrlEncoded"
+			for _, f := range req.Body.URLEncoded {
+				bru.FormUrlEncoded = append(bru.FormUrlEncoded, KeyValue{Key: f.Key, Value: f.Value, Enabled: !f.Disabled})
+			}
+		case "formdata":
+			bru.BodyMode = "multipartForm"
+			for _, f := range req.Body.FormData {
+				value := f.Value
+				if f.Type == "file" {
+					value = "@file(" + strings.Join(formFileSources(f.Src), "|") + ")"
+				}
+				bru.MultipartForm = append(bru.MultipartForm, KeyValue{Key: f.Key, Value: value, Enabled: !f.Disabled})
+			}
+		case "graphql":
+			bru.Type = "graphql"
+			bru.BodyMode = "graphql"
+			if req.Body.GraphQL != nil {
+				bru.Body = textBlock(req.Body.GraphQL.Query)
+				bru.GraphqlVars = textBlock(req.Body.GraphQL.Variables)
+			}
+		}
+	}
+
+	applyPostmanEvents(bru, item.Name, item.Event)
+
+	for _, resp := range item.Response {
+		ex := BruExample{
+			Name: resp.Name,
+			Response: BruResponse{
+				Status:     resp.Code,
+				StatusText: resp.Status,
+				Body:       textBlock(resp.Body),
+			},
+		}
+		if resp.OriginalRequest != nil {
+			ex.Request.Method = strings.ToLower(resp.OriginalRequest.Method)
+			ex.Request.Url = resp.OriginalRequest.Url.Raw
+			for _, h := range resp.OriginalRequest.Header {
+				ex.Request.Headers = append(ex.Request.Headers, KeyValue{Key: h.Key, Value: h.Value, Enabled: !h.Disabled})
+			}
+			for _, q := range resp.OriginalRequest.Url.Query {
+				ex.Request.Query = append(ex.Request.Query, KeyValue{Key: q.Key, Value: q.Value, Enabled: !q.Disabled})
+			}
+			if resp.OriginalRequest.Body != nil && resp.OriginalRequest.Body.Raw != "" {
+				ex.Request.BodyMode = rawBodyMode(resp.OriginalRequest.Body)
+				ex.Request.Body = textBlock(resp.OriginalRequest.Body.Raw)
+			}
+		}
+		for _, h := range resp.Header {
+			ex.Response.Headers = append(ex.Response.Headers, KeyValue{Key: h.Key, Value: h.Value, Enabled: !h.Disabled})
+		}
+		bru.Examples = append(bru.Examples, ex)
+	}
+
+	// Dynamic variables ({{$guid}}) get their Bruno names
+	dynamic := newDynamicTranslator(PostmanToBrunoDynamic)
+	bru = mapBru(bru, dynamic.replace)
+	dynamic.warnUnmapped(item.Name, "Bruno")
+	return bru
+}
+
+// PostmanEnvToBru converts a Postman environment to a Bruno environment.
+// Secrets are only listed in vars:secret, Bruno keeps their values out of
+// environment files.
+func PostmanEnvToBru(pmEnv *PostmanEnvironment) *BruEnvironment {
+	env := &BruEnvironment{
+		Name: pmEnv.Name,
+		Vars: []KeyValue{},
+	}
+	for _, v := range pmEnv.Values {
+		if v.Type == "secret" {
+			env.Secrets = append(env.Secrets, KeyValue{Key: v.Key, Enabled: v.Enabled})
+			continue
+		}
+		env.Vars = append(env.Vars, KeyValue{Key: v.Key, Value: v.Value, Enabled: v.Enabled})
+	}
+	return env
+}
+
+// applyPostmanEvents translates Postman prerequest and test events into
+// Bruno script:pre-request and tests blocks. A test event exported by
+// bru-ship is split back into its post-response script, tests and asserts.
+func applyPostmanEvents(bru *BruFile, name string, events []Event) {
+	for _, event := range events {
+		switch event.Listen {
+		case "prerequest":
+			bru.PreRequestScript = importScript(name, "pre-request script", event.Script.Exec)
+		case "test":
+			for _, section := range splitTestSections(event.Script.Exec) {
+				switch section.name {
+				case testSectionPostResponse:
+					bru.PostResponseScript = importScript(name, "post-response script", section.lines)
+				case testSectionAssert:
+					bru.Asserts = importAsserts(section.lines)
+				default:
+					bru.Tests = importScript(name, "tests", section.lines)
+				}
+			}
+		}
+	}
+}
+
+// importScript translates the lines of a Postman script, "" if it is empty
+func importScript(name string, block string, lines []string) string {
+	script := strings.Join(lines, "\n")
+	if strings.TrimSpace(script) == "" {
+		return ""
+	}
+	translated, untranslated := TranslatePostmanScript(script)
+	warnUntranslated(name, block, untranslated)
+	return textBlock(strings.TrimRight(translated, "\n"))
+}
+
+type testSection struct {
+	name  string
+	lines []string
+}
+
+// splitTestSections splits a test event at the marker comments written by
+// scriptEvents. Lines before any marker are tests.
+func splitTestSections(exec []string) []testSection {
+	sections := []testSection{{name: testSectionTests}}
+	for _, line := range exec {
+		if section, ok := strings.CutPrefix(strings.TrimSpace(line), testSectionMarker); ok {
+			sections = append(sections, testSection{name: section})
+			continue
+		}
+		last := &sections[len(sections)-1]
+		last.lines = append(last.lines, line)
+	}
+	return sections
+}
+
+// compiledAssert matches the first line of an assert compiled by CompileAsserts
+var compiledAssert = regexp.MustCompile(`^pm\.test\(("(?:[^"\\]|\\.)*"), function \(\) \{$`)
+
+// importAsserts rebuilds the assert entries from their compiled pm.test()
+// calls, whose name is the original "key: value" entry
+func importAsserts(lines []string) []KeyValue {
+	var asserts []KeyValue
+	for _, line := range lines {
+		match := compiledAssert.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		entry, err := strconv.Unquote(match[1])
+		if err != nil {
+			continue
+		}
+		if key, value, ok := strings.Cut(entry, ": "); ok {
+			asserts = append(asserts, KeyValue{Key: key, Value: value, Enabled: true})
+		}
+	}
+	return asserts
+}
+
+// bruSettingsFromPostman maps Postman's protocolProfileBehavior back to the
+// settings block, the reverse of settingsBehavior
+func bruSettingsFromPostman(behavior *ProtocolProfileBehavior) []KeyValue {
+	if behavior == nil {
+		return nil
+	}
+	var settings []KeyValue
+	if behavior.DisableUrlEncoding {
+		settings = append(settings, KeyValue{Key: "encodeUrl", Value: "false", Enabled: true})
+	}
+	if behavior.FollowRedirects != nil {
+		settings = append(settings, KeyValue{Key: "followRedirects", Value: strconv.FormatBool(*behavior.FollowRedirects), Enabled: true})
+	}
+	if behavior.MaxRedirects != nil {
+		settings = append(settings, KeyValue{Key: "maxRedirects", Value: strconv.Itoa(*behavior.MaxRedirects), Enabled: true})
+	}
+	return settings
+}
+
+// rawBodyMode picks the Bruno body mode of a Postman raw body
+func rawBodyMode(body *Body) string {
+	if raw, ok := body.Options["raw"].(map[string]interface{}); ok {
+		switch raw["language"] {
+		case "json":
+			return "json"
+		case "xml":
+			return "xml"
+		case "text", "html", "javascript":
+			return "text"
+		}
+	}
+	if trimmed := strings.TrimSpace(body.Raw); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return "json"
+	}
+	return "text"
+}
+
+// formFileSources returns the file paths of a Postman formdata file field
+func formFileSources(src interface{}) []string {
+	switch v := src.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		var files []string
+		for _, f := range v {
+			files = append(files, scalarString(f))
+		}
+		return files
+	}
+	return nil
+}
+
+// textBlock normalizes text block content so it ends with a newline, as the parser returns it
+func textBlock(text string) string {
+	if text == "" || strings.HasSuffix(text, "\n") {
+		return text
+	}
+	return text + "\n"
+}
+
+// sanitizeFileName removes the characters that are not allowed in file names
+func sanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 32 {
+			return '-'
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." {
+		name = "Untitled"
+	}
+	return name
+}
+
+// uniqueFileName appends a counter to names already used in the same directory
+func uniqueFileName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[strings.ToLower(unique)] || strings.EqualFold(unique, "folder") || strings.EqualFold(unique, "collection"); i++ {
+		unique = fmt.Sprintf("%s (%d)", name, i)
+	}
+	used[strings.ToLower(unique)] = true
+	return unique
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPostmanItemToBru_RoundTrip(t *testing.T) {
+	bru, err := ParseBruFile(filepath.Join("testdata", "create-user.bru"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Inherited auth is resolved during the conversion, use an explicit one
+	bru.Auth = map[string]string{"mode": "bearer", "token": "{{token}}"}
+	// Only the settings with a Postman equivalent make it back
+	bru.Settings = []KeyValue{
+		{Key: "encodeUrl", Value: "false", Enabled: true},
+		{Key: "followRedirects", Value: "false", Enabled: true},
+		{Key: "maxRedirects", Value: "3", Enabled: true},
+	}
+
+	item := BruToPostman(bru, Config{}, nil)
+
+	// Go through JSON like a real import
+	data, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Item
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	imported := PostmanItemToBru(decoded, bru.Seq)
+
+	checks := []struct {
+		field     string
+		got, want interface{}
+	}{
+		{"Name", imported.Name, bru.Name},
+		{"Method", imported.Method, bru.Method},
+		{"Url", imported.Url, bru.Url},
+		{"BodyMode", imported.BodyMode, bru.BodyMode},
+		{"Body", imported.Body, bru.Body},
+		{"Headers", imported.Headers, bru.Headers},
+		{"Query", imported.Query, bru.Query},
+		{"Params", imported.Params, bru.Params},
+		{"Auth", imported.Auth, bru.Auth},
+		{"Docs", imported.Docs, bru.Docs},
+		{"PreRequestScript", imported.PreRequestScript, bru.PreRequestScript},
+		{"PostResponseScript", imported.PostResponseScript, bru.PostResponseScript},
+		{"Tests", imported.Tests, bru.Tests},
+		{"Asserts", imported.Asserts, bru.Asserts},
+		{"Examples", imported.Examples, bru.Examples},
+		{"Settings", imported.Settings, bru.Settings},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s mismatch\nwant: %#v\ngot:  %#v", c.field, c.want, c.got)
+		}
+	}
+}
+
+func TestImportPostman(t *testing.T) {
+	collectionJSON := `{
+  "info": {"name": "Partner API", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "auth": {"type": "apikey", "apikey": [{"key": "key", "value": "X-Api-Key"}, {"key": "value", "value": "{{apiKey}}"}, {"key": "in", "value": "header"}]},
+  "variable": [{"key": "baseUrl", "value": "https://partner.example.com"}, {"key": "retries", "value": 3}],
+  "item": [
+    {
+      "name": "Orders",
+      "item": [
+        {
+          "name": "List Orders",
+          "request": {"method": "GET", "header": [], "url": "{{baseUrl}}/orders?page=1"}
+        },
+        {
+          "name": "Login",
+          "request": {
+            "method": "POST",
+            "header": [],
+            "url": {"raw": "{{baseUrl}}/login"},
+            "auth": {"type": "oauth2", "oauth2": [{"key": "grant_type", "value": "client_credentials"}, {"key": "accessTokenUrl", "value": "https://auth.example.com/token"}, {"key": "useBrowser", "value": false}]},
+            "body": {"mode": "urlencoded", "urlencoded": [{"key": "scope", "value": "orders", "type": "text"}]}
+          }
+        }
+      ]
+    },
+    {
+      "name": "Ping",
+      "request": {"method": "GET", "header": [], "url": "{{baseUrl}}/ping"}
+    }
+  ]
+}`
+	var collection PostmanCollection
+	if err := json.Unmarshal([]byte(collectionJSON), &collection); err != nil {
+		t.Fatalf("Failed to parse collection: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	if err := ImportPostman(&collection, outputDir, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, file := range []string{"bruno.json", "collection.bru", "Orders/folder.bru", "Orders/List Orders.bru", "Orders/Login.bru", "Ping.bru"} {
+		if _, err := os.Stat(filepath.Join(outputDir, file)); err != nil {
+			t.Errorf("Expected %s to be written: %v", file, err)
+		}
+	}
+
+	collectionBru, err := ParseBruFile(filepath.Join(outputDir, "collection.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collectionBru.Auth["mode"] != "apikey" || collectionBru.Auth["key"] != "X-Api-Key" {
+		t.Errorf("Unexpected collection auth: %v", collectionBru.Auth)
+	}
+	if len(collectionBru.Vars) != 2 || collectionBru.Vars[1].Value != "3" {
+		t.Errorf("Unexpected collection vars: %+v", collectionBru.Vars)
+	}
+
+	list, err := ParseBruFile(filepath.Join(outputDir, "Orders", "List Orders.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Url != "{{baseUrl}}/orders?page=1" || list.Seq != 1 || list.Auth["mode"] != "inherit" {
+		t.Errorf("Unexpected request: %+v", list)
+	}
+	if len(list.Query) != 1 || list.Query[0].Key != "page" {
+		t.Errorf("Expected query params from the url, got %+v", list.Query)
+	}
+
+	login, err := ParseBruFile(filepath.Join(outputDir, "Orders", "Login.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if login.Seq != 2 || login.BodyMode != "formUrlEncoded" || len(login.FormUrlEncoded) != 1 {
+		t.Errorf("Unexpected login request: %+v", login)
+	}
+	if login.Auth["mode"] != "oauth2" || login.Auth["grant_type"] != "client_credentials" || login.Auth["access_token_url"] != "https://auth.example.com/token" {
+		t.Errorf("Unexpected login auth: %v", login.Auth)
+	}
+
+	// Converting the imported collection back gives the same items, the
+	// top-level request included
+	exported, err := WalkAndConvert(Config{Input: outputDir, KeepFolders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exported.Item) != 2 || exported.Item[0].Name != "Orders" || len(exported.Item[0].Item) != 2 || exported.Item[1].Name != "Ping" {
+		t.Errorf("Unexpected exported items: %+v", exported.Item)
+	}
+}
+
+func TestPostmanEnvToBru(t *testing.T) {
+	pmEnv := &PostmanEnvironment{
+		Name: "Staging",
+		Values: []EnvironmentValue{
+			{Key: "baseUrl", Value: "https://staging.example.com", Type: "default", Enabled: true},
+			{Key: "apiKey", Value: "leaked", Type: "secret", Enabled: true},
+		},
+	}
+
+	env := PostmanEnvToBru(pmEnv)
+	if len(env.Secrets) != 1 || env.Secrets[0].Key != "apiKey" {
+		t.Errorf("Expected apiKey to be a secret, got %v", env.Secrets)
+	}
+	if len(env.Vars) != 1 || strings.Contains(FormatEnvironment(env), "leaked") {
+		t.Errorf("Expected secret value to be left out, got %+v", env.Vars)
+	}
+}
+
+func TestPostmanItemToBru_DynamicVariables(t *testing.T) {
+	item := Item{
+		Name: "Create",
+		Request: &Request{
+			Method: "POST",
+			Url:    Url{Raw: "https://api.example.com/{{$randomUUID}}"},
+			Header: []Header{{Key: "X-Id", Value: "{{$notInBruno}}"}},
+		},
+	}
+
+	bru := PostmanItemToBru(item, 1)
+	if bru.Url != "https://api.example.com/{{$randomUUID}}" {
+		t.Errorf("Unexpected URL: %s", bru.Url)
+	}
+	if bru.Headers[0].Value != "{{$notInBruno}}" {
+		t.Errorf("Expected the unknown dynamic variable to be kept, got %s", bru.Headers[0].Value)
+	}
+}
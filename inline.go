@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// inliner replaces {{variables}} with their values for -inline-vars. Values
+// may reference other variables, they are resolved too. Variables without a
+// value are kept as they are and recorded in unresolved.
+type inliner struct {
+	values     map[string]string
+	unresolved map[string]bool
+	// keep holds the variables deliberately left to Postman, such as withheld
+	// secrets, and kept the ones found so far
+	keep map[string]bool
+	kept map[string]bool
+}
+
+func newInliner(values map[string]string) *inliner {
+	return &inliner{values: values, unresolved: make(map[string]bool), keep: make(map[string]bool), kept: make(map[string]bool)}
+}
+
+// replace substitutes every variable of s
+func (in *inliner) replace(s string) string {
+	return in.resolve(s, nil)
+}
+
+// resolve substitutes the variables of s. stack holds the variables being
+// resolved, to stop on circular references.
+func (in *inliner) resolve(s string, stack []string) string {
+	return variableReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := variableReference.FindStringSubmatch(ref)[1]
+		if in.keep[name] {
+			in.kept[name] = true
+			return ref
+		}
+		value, ok := in.values[name]
+		if !ok || contains(stack, name) {
+			// Dynamic variables ({{$guid}}) are generated by Postman at run time
+			if !isDynamicVariable(name) {
+				in.unresolved[name] = true
+			}
+			return ref
+		}
+		return in.resolve(value, append(stack, name))
+	})
+}
+
+// mapKeyValues applies replace to the keys and values of a list
+func mapKeyValues(list []KeyValue, replace func(string) string) []KeyValue {
+	if list == nil {
+		return nil
+	}
+	replaced := make([]KeyValue, len(list))
+	for i, kv := range list {
+		replaced[i] = KeyValue{Key: replace(kv.Key), Value: replace(kv.Value), Enabled: kv.Enabled}
+	}
+	return replaced
+}
+
+// mapAuth applies replace to the values of an auth block
+func mapAuth(auth map[string]string, replace func(string) string) map[string]string {
+	if auth == nil {
+		return nil
+	}
+	replaced := make(map[string]string, len(auth))
+	for k, v := range auth {
+		replaced[k] = replace(v)
+	}
+	return replaced
+}
+
+// mapBru returns a copy of a request with replace applied to the URL,
+// headers, params, bodies, auth, docs and examples. Scripts, tests and
+// asserts are code and are left as they are.
+func mapBru(bru *BruFile, replace func(string) string) *BruFile {
+	mapped := *bru
+	mapped.Url = replace(bru.Url)
+	mapped.Headers = mapKeyValues(bru.Headers, replace)
+	mapped.Query = mapKeyValues(bru.Query, replace)
+	mapped.Params = mapKeyValues(bru.Params, replace)
+	mapped.Body = replace(bru.Body)
+	mapped.GraphqlVars = replace(bru.GraphqlVars)
+	mapped.FormUrlEncoded = mapKeyValues(bru.FormUrlEncoded, replace)
+	mapped.MultipartForm = mapKeyValues(bru.MultipartForm, replace)
+	mapped.Auth = mapAuth(bru.Auth, replace)
+	mapped.Docs = replace(bru.Docs)
+
+	mapped.Examples = make([]BruExample, len(bru.Examples))
+	for i, ex := range bru.Examples {
+		ex.Request.Url = replace(ex.Request.Url)
+		ex.Request.Headers = mapKeyValues(ex.Request.Headers, replace)
+		ex.Request.Query = mapKeyValues(ex.Request.Query, replace)
+		ex.Request.Body = replace(ex.Request.Body)
+		ex.Response.Headers = mapKeyValues(ex.Response.Headers, replace)
+		ex.Response.Body = replace(ex.Response.Body)
+		mapped.Examples[i] = ex
+	}
+	return &mapped
+}
+
+// warnUnresolved reports the variables left in the output of a request
+func (in *inliner) warnUnresolved(name string) {
+	if len(in.kept) > 0 {
+		fmt.Printf("Warning: %s: secret variables kept as references, their values are withheld: %s\n", name, variableList(in.kept))
+	}
+	if len(in.unresolved) > 0 {
+		fmt.Printf("Warning: %s: unresolved variables left in the output: %s\n", name, variableList(in.unresolved))
+	}
+}
+
+// variableList returns the sorted {{references}} of a set of variables
+func variableList(variables map[string]bool) string {
+	var refs []string
+	for variable := range variables {
+		refs = append(refs, "{{"+variable+"}}")
+	}
+	sort.Strings(refs)
+	return strings.Join(refs, ", ")
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInliner(t *testing.T) {
+	in := newInliner(map[string]string{
+		"host":   "{{scheme}}://{{domain}}",
+		"scheme": "https",
+		"domain": "api.example.com",
+		"a":      "{{b}}",
+		"b":      "{{a}}",
+	})
+
+	if got := in.replace("{{host}}/users/{{ id }}?ts={{$timestamp}}"); got != "https://api.example.com/users/{{ id }}?ts={{$timestamp}}" {
+		t.Errorf("Unexpected replacement: %s", got)
+	}
+	if got := in.replace("{{a}}"); got != "{{a}}" {
+		t.Errorf("Expected a circular reference to be kept, got %s", got)
+	}
+	if !in.unresolved["id"] || !in.unresolved["a"] || in.unresolved["$timestamp"] || len(in.unresolved) != 2 {
+		t.Errorf("Unexpected unresolved variables: %v", in.unresolved)
+	}
+}
+
+func TestBruToPostman_InlineVars(t *testing.T) {
+	bru := &BruFile{
+		Name:             "Get User",
+		Method:           "GET",
+		Url:              "{{baseUrl}}/users/:id?expand={{expand}}",
+		Headers:          []KeyValue{{Key: "X-Tenant", Value: "{{tenant}}", Enabled: true}},
+		Query:            []KeyValue{{Key: "expand", Value: "{{expand}}", Enabled: true}},
+		Params:           []KeyValue{{Key: "id", Value: "{{userId}}", Enabled: true}},
+		Vars:             []KeyValue{{Key: "userId", Value: "42", Enabled: true}, {Key: "token", Value: "res.body.token", Enabled: true}},
+		PostResponseVars: []KeyValue{{Key: "token", Value: "res.body.token", Enabled: true}},
+		Docs:             "Calls {{baseUrl}}",
+		Auth:             map[string]string{"mode": "inherit"},
+		Examples: []BruExample{{
+			Name:     "OK",
+			Request:  BruRequest{Method: "GET", Url: "{{baseUrl}}/users/42", Query: []KeyValue{{Key: "tenant", Value: "{{tenant}}", Enabled: true}}},
+			Response: BruResponse{Status: 200, Body: `{"tenant": "{{tenant}}"}`},
+		}},
+	}
+	config := Config{
+		InlineVars: true,
+		Variables: NewVariableResolver([]KeyValue{
+			{Key: "baseUrl", Value: "{{host}}/v1", Enabled: true},
+			{Key: "host", Value: "https://api.example.com", Enabled: true},
+			{Key: "tenant", Value: "collection", Enabled: true},
+		}, nil, map[string]string{"tenant": "acme", "expand": "roles"}, nil),
+	}
+	parentAuth := map[string]string{"mode": "bearer", "token": "{{token}}"}
+
+	item := BruToPostman(bru, config, parentAuth)
+	content, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(content)
+
+	for _, expected := range []string{
+		`"raw":"https://api.example.com/v1/users/:id?expand=roles"`,
+		`"value":"acme"`,
+		`"value":"42"`,
+		`Calls https://api.example.com/v1`,
+		`{\"tenant\": \"acme\"}`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %s in %s", expected, output)
+		}
+	}
+	// token is set by the response, it is left to Postman
+	if !strings.Contains(output, `"value":"{{token}}"`) {
+		t.Errorf("Expected the unresolved token to be kept, got %s", output)
+	}
+	if query := item.Response[0].OriginalRequest.Url.Query; len(query) != 1 || query[0].Value != "acme" {
+		t.Errorf("Expected the example query param to be inlined, got %+v", query)
+	}
+	if bru.Url != "{{baseUrl}}/users/:id?expand={{expand}}" || parentAuth["token"] != "{{token}}" {
+		t.Error("Expected the parsed request and the parent auth to be left untouched")
+	}
+}
+
+// Withheld environment secrets are empty in the environment, -inline-vars
+// must leave them as references instead of inlining the empty value
+func TestBruToPostman_InlineVarsSecrets(t *testing.T) {
+	bru := &BruFile{
+		Name:    "Get User",
+		Method:  "GET",
+		Url:     "{{baseUrl}}/users?key={{apiKey}}",
+		Headers: []KeyValue{{Key: "X-Pin", Value: "{{pin}}", Enabled: true}},
+		Vars:    []KeyValue{{Key: "pin", Value: "1234", Enabled: true}},
+		Auth:    map[string]string{"mode": "bearer", "token": "{{token}}"},
+	}
+	env := map[string]string{"baseUrl": "https://api.example.com", "apiKey": "", "token": "", "pin": ""}
+	config := Config{
+		InlineVars: true,
+		Secrets:    map[string]bool{"apiKey": true, "token": true, "pin": true},
+		Variables:  NewVariableResolver(nil, env, nil, nil),
+	}
+
+	item := BruToPostman(bru, config, nil)
+	if item.Request.Url.Raw != "https://api.example.com/users?key={{apiKey}}" {
+		t.Errorf("Expected the secret to be kept in the URL, got %s", item.Request.Url.Raw)
+	}
+	if item.Request.Auth.Bearer[0].Value != "{{token}}" {
+		t.Errorf("Expected the secret to be kept in the auth, got %+v", item.Request.Auth.Bearer)
+	}
+	// A request var of the same name isn't the withheld secret
+	if item.Request.Header[0].Value != "1234" {
+		t.Errorf("Expected the request var to be inlined, got %s", item.Request.Header[0].Value)
+	}
+
+	in := newInliner(map[string]string{"apiKey": ""})
+	in.keep["apiKey"] = true
+	in.replace("{{apiKey}}")
+	if !in.kept["apiKey"] || len(in.unresolved) != 0 {
+		t.Errorf("Expected apiKey to be reported as kept, got kept %v, unresolved %v", in.kept, in.unresolved)
+	}
+}
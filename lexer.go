@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies a line of a .bru file
+type tokenKind int
+
+const (
+	tokenEOF   tokenKind = iota
+	tokenBlank           // empty line
+	tokenOpen            // "name {", "name: {" or "name [" opening a block
+	tokenClose           // "}" or "]"
+	tokenPair            // "key: value"
+	tokenText            // anything else
+)
+
+// token is a line of a .bru file. The grammar is line based: every token
+// spans a whole line, and text blocks use the raw line instead of its kind.
+type token struct {
+	kind   tokenKind
+	line   int    // 1-based line number
+	raw    string // the line without its line ending
+	indent string // leading whitespace
+	name   string // block name or pair key
+	value  string // pair value, or the items following "[" on the same line
+	delim  byte   // '{' or '[' for tokenOpen, '}' or ']' for tokenClose
+}
+
+// column returns the 1-based column of the first character of the token
+func (t token) column() int {
+	return len(t.indent) + 1
+}
+
+// bruLexer splits a .bru file into line tokens, with lookahead
+type bruLexer struct {
+	reader  *lineReader
+	line    int
+	pending []token
+}
+
+func newBruLexer(r io.Reader) *bruLexer {
+	return &bruLexer{reader: newLineReader(r)}
+}
+
+// next consumes and returns the next token
+func (l *bruLexer) next() token {
+	tok := l.peekN(0)
+	l.pending = l.pending[1:]
+	return tok
+}
+
+// peek returns the next token without consuming it
+func (l *bruLexer) peek() token {
+	return l.peekN(0)
+}
+
+// peekN returns the n-th token ahead (0 is the next one) without consuming it
+func (l *bruLexer) peekN(n int) token {
+	for len(l.pending) <= n {
+		if !l.reader.Scan() {
+			l.pending = append(l.pending, token{kind: tokenEOF, line: l.line + 1})
+			continue
+		}
+		l.line++
+		l.pending = append(l.pending, lexLine(l.reader.Text(), l.line))
+	}
+	return l.pending[n]
+}
+
+// peekSignificant returns the next token that isn't a blank line, without consuming anything
+func (l *bruLexer) peekSignificant() token {
+	for n := 0; ; n++ {
+		if tok := l.peekN(n); tok.kind != tokenBlank {
+			return tok
+		}
+	}
+}
+
+// err returns the read error of the underlying file, if any
+func (l *bruLexer) err() error {
+	return l.reader.Err()
+}
+
+// lexLine classifies a line of a .bru file
+func lexLine(raw string, line int) token {
+	trimmed := strings.TrimSpace(raw)
+	tok := token{line: line, raw: raw, indent: raw[:len(raw)-len(strings.TrimLeftFunc(raw, unicode.IsSpace))]}
+
+	switch {
+	case trimmed == "":
+		tok.kind = tokenBlank
+	case trimmed == "}" || trimmed == "]":
+		tok.kind = tokenClose
+		tok.delim = trimmed[0]
+	case strings.HasSuffix(trimmed, " {"):
+		tok.kind = tokenOpen
+		tok.delim = '{'
+		tok.name = strings.TrimSpace(strings.TrimSuffix(trimmed, " {"))
+	case isArrayOpening(trimmed):
+		name, items, _ := strings.Cut(trimmed, " [")
+		tok.kind = tokenOpen
+		tok.delim = '['
+		tok.name = name
+		tok.value = strings.TrimSpace(items)
+	case strings.Contains(trimmed, ":"):
+		key, value, _ := strings.Cut(trimmed, ":")
+		tok.kind = tokenPair
+		tok.name = strings.TrimSpace(key)
+		tok.value = strings.TrimSpace(value)
+	default:
+		tok.kind = tokenText
+	}
+	return tok
+}
+
+// isArrayOpening reports whether a line opens an array block ("vars:secret [")
+func isArrayOpening(trimmed string) bool {
+	name, _, ok := strings.Cut(trimmed, " [")
+	return ok && name != "" && !strings.ContainsAny(name, " \t") && !strings.HasSuffix(name, ":")
+}
+
+// lineReader reads a file line by line like bufio.Scanner, without its
+// 64 KB limit on the length of a line
+type lineReader struct {
+	reader *bufio.Reader
+	line   []byte
+	err    error
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{reader: bufio.NewReader(r)}
+}
+
+// Scan reads the next line, it returns false at the end of the input or on error
+func (l *lineReader) Scan() bool {
+	l.line = l.line[:0]
+	for {
+		// Lines longer than the buffer are read in several chunks
+		chunk, err := l.reader.ReadSlice('\n')
+		l.line = append(l.line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF {
+			if len(l.line) == 0 {
+				return false
+			}
+			break
+		}
+		if err != nil {
+			l.err = err
+			return false
+		}
+		break
+	}
+	l.line = bytes.TrimSuffix(bytes.TrimSuffix(l.line, []byte("\n")), []byte("\r"))
+	return true
+}
+
+// Text returns the last line read, without its line ending
+func (l *lineReader) Text() string {
+	return string(l.line)
+}
+
+// Err returns the first read error, io.EOF isn't an error
+func (l *lineReader) Err() error {
+	return l.err
+}
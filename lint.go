@@ -0,0 +1,502 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LintRule is a check run by "bru-ship lint"
+type LintRule struct {
+	ID          string
+	Description string
+	Severity    string // default severity: error, warning, info or off
+}
+
+var lintRules = []LintRule{
+	{ID: "syntax-error", Description: "Malformed .bru file", Severity: "error"},
+	{ID: "missing-name", Description: "Request without a meta name", Severity: "error"},
+	{ID: "duplicate-name", Description: "Several requests with the same name in a folder", Severity: "warning"},
+	{ID: "duplicate-seq", Description: "Several requests (or folders) with the same seq in a folder", Severity: "warning"},
+	{ID: "url-scheme", Description: "URL that doesn't start with a {{variable}} or a scheme", Severity: "warning"},
+	{ID: "hardcoded-secret", Description: "Hard-coded token, key or password in headers or auth", Severity: "error"},
+	{ID: "undefined-variable", Description: "{{variable}} defined in no environment, collection.bru, folder.bru or request", Severity: "warning"},
+	{ID: "invalid-json", Description: "body:json that isn't valid JSON", Severity: "error"},
+}
+
+var lintSeverities = map[string]bool{"error": true, "warning": true, "info": true, "off": true}
+
+// LintIssue is a problem found by the linter
+type LintIssue struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	File     string `json:"file"` // relative to the collection root, with forward slashes
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+var (
+	urlScheme = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*://`)
+	// Variables set at runtime by scripts (bru.setVar, bru.setEnvVar...)
+	scriptSetVar      = regexp.MustCompile("bru\\.set\\w*Var\\(\\s*[\"'`]([^\"'`]+)[\"'`]")
+	secretHeaderNames = regexp.MustCompile(`(?i)^(authorization|proxy-authorization|cookie)$|token|secret|password|api-?key`)
+	secretAuthKeys    = map[string]bool{"token": true, "password": true, "client_secret": true, "secretAccessKey": true, "sessionToken": true}
+)
+
+// runLint implements "bru-ship lint": check a collection for common mistakes
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	var input string
+	var format string
+	var output string
+	var severities arrayFlags
+	fs.StringVar(&input, "input", ".", "Root directory of Bruno collection")
+	fs.StringVar(&format, "format", "text", "Output format: text, json or sarif")
+	fs.StringVar(&output, "output", "", "Write the report to this file instead of the standard output")
+	fs.Var(&severities, "severity", "Rule severity in format rule=error|warning|info|off (can be repeated or comma-separated)")
+	fs.Parse(args)
+
+	severityMap, err := parseLintSeverities(severities)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	if format != "text" && format != "json" && format != "sarif" {
+		fmt.Printf("Error: Unknown format: %s (use text, json or sarif)\n", format)
+		return 1
+	}
+
+	issues, err := LintCollection(input, severityMap)
+	if err != nil {
+		fmt.Printf("Error linting %s: %v\n", input, err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			fmt.Printf("Error creating output file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch format {
+	case "json":
+		err = writeLintJSON(w, issues)
+	case "sarif":
+		err = writeLintJSON(w, LintSarif(issues, severityMap))
+	default:
+		writeLintText(w, issues)
+	}
+	if err != nil {
+		fmt.Printf("Error writing report: %v\n", err)
+		return 1
+	}
+
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseLintSeverities parses the rule=severity overrides of the -severity flag
+func parseLintSeverities(list []string) (map[string]string, error) {
+	known := make(map[string]bool)
+	for _, rule := range lintRules {
+		known[rule.ID] = true
+	}
+
+	severities := make(map[string]string)
+	for _, entry := range list {
+		for _, setting := range strings.Split(entry, ",") {
+			rule, severity, ok := strings.Cut(strings.TrimSpace(setting), "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid severity '%s', use rule=severity", setting)
+			}
+			if !known[rule] {
+				return nil, fmt.Errorf("unknown lint rule '%s'", rule)
+			}
+			if !lintSeverities[severity] {
+				return nil, fmt.Errorf("unknown severity '%s' for rule '%s' (use error, warning, info or off)", severity, rule)
+			}
+			severities[rule] = severity
+		}
+	}
+	return severities, nil
+}
+
+// lintSeverity returns the configured severity of a rule
+func lintSeverity(rule string, severities map[string]string) string {
+	if severity, ok := severities[rule]; ok {
+		return severity
+	}
+	for _, r := range lintRules {
+		if r.ID == rule {
+			return r.Severity
+		}
+	}
+	return "warning"
+}
+
+// lintFile is a parsed .bru file of the collection
+type lintFile struct {
+	rel     string
+	content string
+	bru     *BruFile
+	request bool
+	scopes  []map[string]bool // variables of collection.bru and the parent folder.bru files
+}
+
+type linter struct {
+	root       string
+	severities map[string]string
+	files      []*lintFile
+	defined    map[string]bool // environment and runtime variables
+	issues     []LintIssue
+}
+
+// LintCollection runs the lint rules over a Bruno collection.
+// severities overrides the default severity of the rules, "off" disables a rule.
+func LintCollection(root string, severities map[string]string) ([]LintIssue, error) {
+	l := &linter{root: root, severities: severities, defined: make(map[string]bool)}
+
+	// Environment variables
+	envFiles, _ := filepath.Glob(filepath.Join(root, "environments", "*.bru"))
+	for _, envFile := range envFiles {
+		env, err := ParseEnvironment(envFile)
+		if errs, ok := err.(ParseErrors); ok {
+			// The partially parsed environment still defines variables
+			for _, e := range errs {
+				l.report("syntax-error", l.relative(envFile), e.Line, e.Message)
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		for _, v := range allVars(env) {
+			l.defined[v.Key] = true
+		}
+	}
+
+	var scopes []map[string]bool
+	collectionPath := filepath.Join(root, "collection.bru")
+	if _, err := os.Stat(collectionPath); err == nil {
+		file, err := l.load(collectionPath, false, nil)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, keySet(file.bru.Vars))
+	}
+	if err := l.walk(root, scopes); err != nil {
+		return nil, err
+	}
+
+	// Variables set by scripts and vars:post-response exist at runtime
+	for _, file := range l.files {
+		for _, v := range file.bru.PostResponseVars {
+			l.defined[v.Key] = true
+		}
+		for _, script := range []string{file.bru.PreRequestScript, file.bru.PostResponseScript, file.bru.Tests} {
+			for _, match := range scriptSetVar.FindAllStringSubmatch(script, -1) {
+				l.defined[match[1]] = true
+			}
+		}
+	}
+
+	for _, file := range l.files {
+		l.check(file)
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].File != l.issues[j].File {
+			return l.issues[i].File < l.issues[j].File
+		}
+		return l.issues[i].Line < l.issues[j].Line
+	})
+	return l.issues, nil
+}
+
+func (l *linter) load(path string, request bool, scopes []map[string]bool) (*lintFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rel, _ := filepath.Rel(l.root, path)
+	rel = filepath.ToSlash(rel)
+	bru, err := ParseBruFile(path)
+	if errs, ok := err.(ParseErrors); ok {
+		// The partially parsed file still defines variables, but isn't checked
+		for _, e := range errs {
+			l.report("syntax-error", rel, e.Line, e.Message)
+		}
+		return &lintFile{rel: rel, content: string(content), bru: bru, request: request, scopes: scopes}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	file := &lintFile{rel: rel, content: string(content), bru: bru, request: request, scopes: scopes}
+	l.files = append(l.files, file)
+	return file, nil
+}
+
+// walk loads the folder.bru and requests of a directory and checks the
+// names and seq numbers of its entries
+func (l *linter) walk(dir string, scopes []map[string]bool) error {
+	folderPath := filepath.Join(dir, "folder.bru")
+	if _, err := os.Stat(folderPath); err == nil && dir != l.root {
+		file, err := l.load(folderPath, false, scopes)
+		if err != nil {
+			return err
+		}
+		scopes = append(append([]map[string]bool{}, scopes...), keySet(file.bru.Vars))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string)
+	requestSeqs := make(map[int]string)
+	folderSeqs := make(map[int]string)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if strings.HasPrefix(entry.Name(), ".") || entry.Name() == "node_modules" || (dir == l.root && entry.Name() == "environments") {
+				continue
+			}
+			if err := l.walk(path, scopes); err != nil {
+				return err
+			}
+			if folder, err := ParseBruFile(filepath.Join(path, "folder.bru")); err == nil && folder.Seq > 0 {
+				rel := l.relative(filepath.Join(path, "folder.bru"))
+				if other, ok := folderSeqs[folder.Seq]; ok {
+					l.report("duplicate-seq", rel, lineOf(l.fileContent(rel), "seq:"), fmt.Sprintf("seq %d is also used by folder %s", folder.Seq, other))
+				} else {
+					folderSeqs[folder.Seq] = entry.Name()
+				}
+			}
+			continue
+		}
+		if !strings.HasSuffix(entry.Name(), ".bru") || entry.Name() == "folder.bru" || entry.Name() == "collection.bru" {
+			continue
+		}
+
+		file, err := l.load(path, true, scopes)
+		if err != nil {
+			return err
+		}
+		if name := file.bru.Name; name != "" {
+			if other, ok := names[name]; ok {
+				l.report("duplicate-name", file.rel, lineOf(file.content, "name:"), fmt.Sprintf("request name '%s' is also used by %s", name, other))
+			} else {
+				names[name] = entry.Name()
+			}
+		}
+		if seq := file.bru.Seq; seq > 0 {
+			if other, ok := requestSeqs[seq]; ok {
+				l.report("duplicate-seq", file.rel, lineOf(file.content, "seq:"), fmt.Sprintf("seq %d is also used by %s", seq, other))
+			} else {
+				requestSeqs[seq] = entry.Name()
+			}
+		}
+	}
+	return nil
+}
+
+func (l *linter) check(file *lintFile) {
+	bru := file.bru
+
+	if file.request {
+		if bru.Name == "" {
+			l.report("missing-name", file.rel, 1, "request has no meta name")
+		}
+		if url := strings.TrimSpace(bru.Url); url == "" {
+			l.report("url-scheme", file.rel, lineOf(file.content, "url:"), "request has no URL")
+		} else if !strings.HasPrefix(url, "{{") && !urlScheme.MatchString(url) {
+			l.report("url-scheme", file.rel, lineOf(file.content, "url:"), fmt.Sprintf("URL '%s' doesn't start with a {{variable}} or a scheme", url))
+		}
+		if bru.BodyMode == "json" && strings.TrimSpace(bru.Body) != "" {
+			var value interface{}
+			if err := json.Unmarshal([]byte(quoteVariables(bru.Body)), &value); err != nil {
+				line := lineOf(file.content, "body:json {")
+				if syntaxErr, ok := err.(*json.SyntaxError); ok && line > 0 {
+					offset := min(max(int(syntaxErr.Offset)-1, 0), len(bru.Body))
+					line += strings.Count(quoteVariables(bru.Body)[:offset], "\n") + 1
+				}
+				l.report("invalid-json", file.rel, line, fmt.Sprintf("body:json is not valid JSON: %v", err))
+			}
+		}
+	}
+
+	// Hard-coded secrets
+	for _, h := range bru.Headers {
+		if secretHeaderNames.MatchString(h.Key) && isHardcoded(h.Value) {
+			l.report("hardcoded-secret", file.rel, lineOf(file.content, h.Key+":"), fmt.Sprintf("header '%s' holds a hard-coded value, use a {{variable}}", h.Key))
+		}
+	}
+	authKeys := make([]string, 0, len(bru.Auth))
+	for key := range bru.Auth {
+		authKeys = append(authKeys, key)
+	}
+	sort.Strings(authKeys)
+	for _, key := range authKeys {
+		secret := secretAuthKeys[key] || (key == "value" && bru.Auth["mode"] == "apikey")
+		if secret && isHardcoded(bru.Auth[key]) {
+			l.report("hardcoded-secret", file.rel, lineOf(file.content, key+":"), fmt.Sprintf("auth %s holds a hard-coded value, use a {{variable}}", key))
+		}
+	}
+
+	// Undefined variables
+	local := keySet(bru.Vars)
+	reported := make(map[string]bool)
+	for _, text := range bruReferenceTexts(bru) {
+		for _, match := range variableReference.FindAllStringSubmatch(text, -1) {
+			name := match[1]
+			if reported[name] || strings.HasPrefix(name, "$") || strings.HasPrefix(name, "process.env.") || local[name] || l.defined[name] {
+				continue
+			}
+			found := false
+			for _, scope := range file.scopes {
+				if scope[name] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				reported[name] = true
+				l.report("undefined-variable", file.rel, lineOf(file.content, match[0]), fmt.Sprintf("variable '%s' is not defined in any environment, collection.bru, folder.bru or the request", name))
+			}
+		}
+	}
+}
+
+func (l *linter) report(rule string, file string, line int, message string) {
+	severity := lintSeverity(rule, l.severities)
+	if severity == "off" {
+		return
+	}
+	l.issues = append(l.issues, LintIssue{Rule: rule, Severity: severity, File: file, Line: line, Message: message})
+}
+
+func (l *linter) relative(path string) string {
+	rel, _ := filepath.Rel(l.root, path)
+	return filepath.ToSlash(rel)
+}
+
+func (l *linter) fileContent(rel string) string {
+	for _, file := range l.files {
+		if file.rel == rel {
+			return file.content
+		}
+	}
+	return ""
+}
+
+// bruReferenceTexts returns the values of a BruFile that are sent with the request
+func bruReferenceTexts(bru *BruFile) []string {
+	texts := []string{bru.Url, bru.Body, bru.GraphqlVars}
+	for _, list := range [][]KeyValue{bru.Headers, bru.Query, bru.Params, bru.FormUrlEncoded, bru.MultipartForm, bru.Vars} {
+		for _, kv := range list {
+			if kv.Enabled {
+				texts = append(texts, kv.Value)
+			}
+		}
+	}
+	for key, value := range bru.Auth {
+		if key != "mode" {
+			texts = append(texts, value)
+		}
+	}
+	return texts
+}
+
+// isHardcoded reports whether a secret value is written in the file instead of taken from a variable
+func isHardcoded(value string) bool {
+	return strings.TrimSpace(value) != "" && !variableReference.MatchString(value)
+}
+
+func keySet(vars []KeyValue) map[string]bool {
+	keys := make(map[string]bool)
+	for _, v := range vars {
+		keys[v.Key] = true
+	}
+	return keys
+}
+
+// lineOf returns the 1-based number of the first line containing needle, 0 if none does
+func lineOf(content string, needle string) int {
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, needle) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func writeLintText(w io.Writer, issues []LintIssue) {
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		location := issue.File
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", issue.File, issue.Line)
+		}
+		fmt.Fprintf(w, "%s: %s: %s [%s]\n", location, issue.Severity, issue.Message, issue.Rule)
+		counts[issue.Severity]++
+	}
+	fmt.Fprintf(w, "%d errors, %d warnings, %d infos\n", counts["error"], counts["warning"], counts["info"])
+}
+
+func writeLintJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// LintSarif converts lint issues to a SARIF 2.1.0 log
+func LintSarif(issues []LintIssue, severities map[string]string) *SarifLog {
+	levels := map[string]string{"error": "error", "warning": "warning", "info": "note", "off": "none"}
+
+	driver := SarifDriver{
+		Name:           "bru-ship",
+		Version:        version,
+		InformationUri: "https://github.com/jonathanhecl/bru-ship",
+		Rules:          []SarifRule{},
+	}
+	for _, rule := range lintRules {
+		driver.Rules = append(driver.Rules, SarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     SarifMessage{Text: rule.Description},
+			DefaultConfiguration: SarifConfiguration{Level: levels[lintSeverity(rule.ID, severities)]},
+		})
+	}
+
+	run := SarifRun{Tool: SarifTool{Driver: driver}, Results: []SarifResult{}}
+	for _, issue := range issues {
+		location := SarifPhysicalLocation{ArtifactLocation: SarifArtifactLocation{URI: issue.File}}
+		if issue.Line > 0 {
+			location.Region = &SarifRegion{StartLine: issue.Line}
+		}
+		run.Results = append(run.Results, SarifResult{
+			RuleID:    issue.Rule,
+			Level:     levels[issue.Severity],
+			Message:   SarifMessage{Text: issue.Message},
+			Locations: []SarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	return &SarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []SarifRun{run},
+	}
+}
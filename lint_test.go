@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeLintCollection(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"collection.bru":         "vars:pre-request {\n  baseUrl: https://api.example.com\n}\n",
+		"environments/Local.bru": "vars {\n  host: localhost\n}\nvars:secret [\n  apiToken\n]\n",
+		"Users/folder.bru":       "meta {\n  name: Users\n  seq: 1\n}\n\nvars:pre-request {\n  userId: 1\n}\n",
+		"Users/Get User.bru": `meta {
+  name: Get User
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/users/{{userId}}?q={{missing}}
+  body: none
+  auth: bearer
+}
+
+auth:bearer {
+  token: abc123
+}
+
+script:post-response {
+  bru.setVar("nextId", res.body.id);
+}
+`,
+		"Users/Get User Copy.bru": `meta {
+  name: Get User
+  type: http
+  seq: 1
+}
+
+post {
+  url: api.example.com/users/{{nextId}}
+  body: json
+  auth: none
+}
+
+headers {
+  Authorization: Bearer {{host}}
+  X-Api-Key: secret
+}
+
+body:json {
+  {
+    "id": {{userId}},
+    "name": "x",
+  }
+}
+`,
+		"Orders/folder.bru": "meta {\n  name: Orders\n  seq: 1\n}\n",
+		"Orders/List.bru":   "meta {\n  type: http\n}\n\nget {\n  url: {{baseUrl}}/orders/{{userId}}?token={{apiToken}}\n  body: none\n  auth: none\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLintCollection(t *testing.T) {
+	dir := writeLintCollection(t)
+
+	issues, err := LintCollection(dir, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, fmt.Sprintf("%s:%d %s %s", issue.File, issue.Line, issue.Severity, issue.Rule))
+	}
+	expected := []string{
+		"Orders/List.bru:1 error missing-name",
+		"Orders/List.bru:6 warning undefined-variable",
+		"Users/Get User Copy.bru:8 warning url-scheme",
+		"Users/Get User Copy.bru:15 error hardcoded-secret",
+		"Users/Get User Copy.bru:22 error invalid-json",
+		"Users/Get User.bru:2 warning duplicate-name",
+		"Users/Get User.bru:4 warning duplicate-seq",
+		"Users/Get User.bru:8 warning undefined-variable",
+		"Users/Get User.bru:14 error hardcoded-secret",
+		"Users/folder.bru:3 warning duplicate-seq",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected issues:\n%s", strings.Join(got, "\n"))
+		for _, issue := range issues {
+			t.Log(issue.File, issue.Line, issue.Message)
+		}
+	}
+}
+
+func TestLintCollection_MalformedEnvironment(t *testing.T) {
+	dir := writeLintCollection(t)
+	envPath := filepath.Join(dir, "environments", "Local.bru")
+	if err := os.WriteFile(envPath, []byte("vars {\n  host: localhost\n  broken\n}\nvars:secret [\n  apiToken\n]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := LintCollection(dir, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	found := false
+	for _, issue := range issues {
+		if issue.File == "environments/Local.bru" && issue.Rule == "syntax-error" && issue.Line == 3 {
+			found = true
+		}
+		// The partially parsed environment still defines its variables
+		if issue.Rule == "undefined-variable" && (strings.Contains(issue.Message, "host") || strings.Contains(issue.Message, "apiToken")) {
+			t.Errorf("Expected the environment variables to be defined, got %s", issue.Message)
+		}
+	}
+	if !found {
+		t.Errorf("Expected a syntax-error issue for the environment, got %v", issues)
+	}
+
+	output := filepath.Join(t.TempDir(), "lint.json")
+	runLint([]string{"-input", dir, "-format", "json", "-output", output})
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report []LintIssue
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Errorf("Invalid JSON output: %v\n%s", err, content)
+	}
+}
+
+func TestRunLint_Sarif(t *testing.T) {
+	dir := writeLintCollection(t)
+	output := filepath.Join(t.TempDir(), "lint.sarif")
+
+	// Downgrading every error rule leaves nothing that fails the run
+	severity := "missing-name=info,hardcoded-secret=off,invalid-json=warning"
+	if code := runLint([]string{"-input", dir, "-format", "sarif", "-output", output, "-severity", severity}); code != 0 {
+		t.Errorf("Expected exit code 0, got %d", code)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log SarifLog
+	if err := json.Unmarshal(content, &log); err != nil {
+		t.Fatalf("Invalid SARIF output: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: %s", content)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(lintRules) {
+		t.Errorf("Expected %d rules, got %d", len(lintRules), len(run.Tool.Driver.Rules))
+	}
+	for _, result := range run.Results {
+		if result.RuleID == "hardcoded-secret" {
+			t.Errorf("Expected hardcoded-secret to be disabled")
+		}
+		if result.RuleID == "missing-name" && result.Level != "note" {
+			t.Errorf("Expected info to map to note, got %s", result.Level)
+		}
+	}
+	if len(run.Results) != 8 {
+		t.Errorf("Expected 8 results, got %d", len(run.Results))
+	}
+
+	if code := runLint([]string{"-input", dir, "-format", "json", "-output", output}); code != 1 {
+		t.Errorf("Expected exit code 1 with error issues, got %d", code)
+	}
+	if code := runLint([]string{"-input", dir, "-severity", "no-such-rule=off"}); code != 1 {
+		t.Errorf("Expected an unknown rule to fail, got %d", code)
+	}
+}
@@ -26,8 +26,18 @@ func (i *arrayFlags) Set(value string) error {
 	return nil
 }
 
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func main() {
-	fmt.Printf("bru-ship v%s\n", version)
 	var folders string
 	var replaces arrayFlags
 	var removes arrayFlags
@@ -43,18 +53,55 @@ func main() {
 	var env string
 	flag.StringVar(&env, "env", "", "Environment name to load variables from (e.g., Production)")
 
+	var inlineSecrets bool
+	flag.BoolVar(&inlineSecrets, "inline-secrets", false, "Write secret variable values (vars:secret) into the output instead of empty placeholders")
+
+	var exportEnvs string
+	flag.StringVar(&exportEnvs, "export-envs", "", "Comma-separated list of environments to export as Postman environment files, or 'all'")
+
 	var ignore string
 	flag.StringVar(&ignore, "ignore", "", "Comma-separated list of endpoint names to ignore")
 
+	var includeTags, excludeTags, methods, excludeFolders string
+	flag.StringVar(&includeTags, "include-tags", "", "Comma-separated list of tags (meta.tags), only endpoints with one of them are exported")
+	flag.StringVar(&excludeTags, "exclude-tags", "", "Comma-separated list of tags (meta.tags) whose endpoints are skipped")
+	flag.StringVar(&methods, "methods", "", "Comma-separated list of HTTP methods to export (e.g., GET,POST)")
+	flag.StringVar(&excludeFolders, "exclude-folders", "", "Comma-separated list of folders to skip, by name at any depth or by relative path")
+
+	var matches, excludes arrayFlags
+	flag.Var(&matches, "match", "Only export endpoints whose name or relative path matches a glob, or a /regex/ (can be repeated)")
+	flag.Var(&excludes, "exclude", "Skip endpoints whose name or relative path matches a glob, or a /regex/ (can be repeated)")
+
 	var verbose bool
 	flag.BoolVar(&verbose, "verbose", false, "Enable verbose logging")
 
 	var keepFolders bool
 	flag.BoolVar(&keepFolders, "keep-folders", false, "Keep folder structure (default is to flatten)")
 
+	var dropDisabled bool
+	flag.BoolVar(&dropDisabled, "drop-disabled", false, "Drop disabled (~) headers, query params and form fields instead of exporting them as disabled")
+
 	var title string
 	flag.StringVar(&title, "title", "", "Title for the generated Postman Collection")
 
+	var format string
+	flag.StringVar(&format, "format", "postman", "Output format: postman (Postman Collection v2.1) or openapi (OpenAPI 3.1)")
+
+	var inlineVars bool
+	flag.BoolVar(&inlineVars, "inline-vars", false, "Replace {{variables}} with their values in URLs, headers, params, bodies, auth, examples and docs")
+
+	var processEnvMode string
+	flag.StringVar(&processEnvMode, "process-env", ProcessEnvRewrite, "What to do with {{process.env.NAME}} references: rewrite (to {{process_env_NAME}} collection variables), resolve (write the values) or keep")
+
+	var dotenvPath string
+	flag.StringVar(&dotenvPath, "dotenv", "", "Extra .env file with process.env values, overriding the collection's .env file and the environment")
+
+	var includeEnvSecrets bool
+	flag.BoolVar(&includeEnvSecrets, "include-env-secrets", false, "Write the process.env values that hold secrets (used by vars:secret, or named like *TOKEN*, *SECRET*, *PASSWORD*, *API_KEY*) instead of empty variables")
+
+	var strict bool
+	flag.BoolVar(&strict, "strict", false, "Abort on the first syntax error instead of skipping malformed .bru files")
+
 	// Filter out standalone "\" arguments which might be passed by PowerShell when copy-pasting multi-line commands
 	var args []string
 	for _, arg := range os.Args {
@@ -64,6 +111,22 @@ func main() {
 	}
 	os.Args = args
 
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "fmt":
+			os.Exit(runFmt(os.Args[2:]))
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "vars":
+			os.Exit(runVars(os.Args[2:]))
+		}
+	}
+
+	// Subcommands print only their own output, "lint -format json" must stay valid JSON
+	fmt.Printf("bru-ship v%s\n", version)
 	flag.Parse()
 
 	if len(os.Args) == 1 {
@@ -71,6 +134,15 @@ func main() {
 		os.Exit(0)
 	}
 
+	if format != "postman" && format != "openapi" {
+		fmt.Printf("Error: Unknown format: %s (use postman or openapi)\n", format)
+		os.Exit(1)
+	}
+	if format == "openapi" {
+		// Folder names become the OpenAPI tags
+		keepFolders = true
+	}
+
 	folderList := []string{}
 	if folders != "" {
 		folderList = strings.Split(folders, ",")
@@ -88,19 +160,50 @@ func main() {
 		ignoreList = strings.Split(ignore, ",")
 	}
 
+	matchPatterns, err := CompilePatterns(matches)
+	if err != nil {
+		fmt.Printf("Error: -match: %v\n", err)
+		os.Exit(1)
+	}
+	excludePatterns, err := CompilePatterns(excludes)
+	if err != nil {
+		fmt.Printf("Error: -exclude: %v\n", err)
+		os.Exit(1)
+	}
+
+	if processEnvMode != ProcessEnvRewrite && processEnvMode != ProcessEnvResolve && processEnvMode != ProcessEnvKeep {
+		fmt.Printf("Error: Unknown -process-env mode: %s (use rewrite, resolve or keep)\n", processEnvMode)
+		os.Exit(1)
+	}
+	processEnvValues, err := LoadProcessEnv(input, dotenvPath)
+	if err != nil {
+		fmt.Printf("Error: Could not load dotenv file %s: %v\n", dotenvPath, err)
+		os.Exit(1)
+	}
+	processEnv := &ProcessEnv{
+		Mode:           processEnvMode,
+		Values:         processEnvValues,
+		IncludeSecrets: includeEnvSecrets,
+		Secrets:        map[string]bool{},
+	}
+
 	replaceMap := make(map[string]string)
+	var envVars map[string]string
+	var envSecrets map[string]bool
 
 	// Load environment variables if specified
 	if env != "" {
 		envPath := filepath.Join(input, "environments", env+".bru")
-		envVars, err := ParseEnvFile(envPath)
+		bruEnv, err := ParseEnvironment(envPath)
 		if err != nil {
 			fmt.Printf("Error: Could not load environment file %s: %v\n", envPath, err)
 			os.Exit(1)
 		} else {
 			fmt.Printf("Loaded environment: %s\n", env)
-			for k, v := range envVars {
-				replaceMap[k] = v
+			envVars = EnvironmentVars(bruEnv, loadDotEnv(input), inlineSecrets)
+			processEnv.Secrets = secretProcessEnvNames(bruEnv)
+			if !inlineSecrets {
+				envSecrets = secretNames(bruEnv)
 			}
 		}
 	}
@@ -113,15 +216,28 @@ func main() {
 	}
 
 	config := Config{
-		Folders:     folderList,
-		Replace:     replaceMap,
-		Remove:      removes,
-		Ignore:      ignoreList,
-		Input:       input,
-		Output:      output,
-		Verbose:     verbose,
-		KeepFolders: keepFolders,
-		Title:       title,
+		Folders:      folderList,
+		Replace:      replaceMap,
+		Environment:  envVars,
+		Remove:       removes,
+		Ignore:       ignoreList,
+		Input:        input,
+		Output:       output,
+		Verbose:      verbose,
+		KeepFolders:  keepFolders,
+		Title:        title,
+		DropDisabled: dropDisabled,
+		Strict:       strict,
+		InlineVars:   inlineVars,
+		Secrets:      envSecrets,
+		ProcessEnv:   processEnv,
+
+		IncludeTags:    splitList(includeTags),
+		ExcludeTags:    splitList(excludeTags),
+		Methods:        splitList(methods),
+		Match:          matchPatterns,
+		Exclude:        excludePatterns,
+		ExcludeFolders: splitList(excludeFolders),
 	}
 
 	// Generate output filename if default or empty
@@ -138,11 +254,17 @@ func main() {
 	fmt.Printf("Starting conversion with config: %+v\n", config)
 
 	collection, err := WalkAndConvert(config)
-	if err != nil {
+	problems, _ := err.(ParseErrors)
+	if err != nil && problems == nil {
 		fmt.Printf("Error converting: %v\n", err)
 		os.Exit(1)
 	}
 
+	var document interface{} = collection
+	if format == "openapi" {
+		document = PostmanToOpenAPI(collection)
+	}
+
 	// Remove existing output file if it exists to ensure a clean overwrite
 	if _, err := os.Stat(output); err == nil {
 		if err := os.Remove(output); err != nil {
@@ -163,11 +285,39 @@ func main() {
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(collection); err != nil {
+	if err := encoder.Encode(document); err != nil {
 		fmt.Printf("Error encoding JSON: %v\n", err)
 		os.Exit(1)
 	}
 
 	absOutput, _ := filepath.Abs(output)
-	fmt.Printf("Conversion completed successfully! Output file: %s\n", absOutput)
+	if len(problems) > 0 {
+		fmt.Printf("Conversion completed with %d skipped files. Output file: %s\n", len(problems.Files()), absOutput)
+	} else {
+		fmt.Printf("Conversion completed successfully! Output file: %s\n", absOutput)
+	}
+
+	// Export environments next to the collection
+	if exportEnvs != "" {
+		envNames := []string{}
+		if exportEnvs != "all" {
+			envNames = splitList(exportEnvs)
+		}
+		written, err := ExportEnvironments(input, envNames, filepath.Dir(absOutput), inlineSecrets, verbose)
+		if err != nil {
+			fmt.Printf("Error exporting environments: %v\n", err)
+			os.Exit(1)
+		}
+		for _, path := range written {
+			fmt.Printf("Environment file: %s\n", path)
+		}
+	}
+
+	if len(problems) > 0 {
+		fmt.Printf("%d syntax errors, the malformed files were skipped:\n", len(problems))
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+		os.Exit(1)
+	}
 }
@@ -1,17 +1,37 @@
 package main
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // BruFile represents the parsed content of a .bru file
 type BruFile struct {
-	Name     string
-	Type     string // http, graphql
-	Url      string
-	Method   string
-	Headers  []KeyValue
-	Body     string
-	Vars     []KeyValue
-	Docs     string
-	Auth     map[string]string
-	Examples []BruExample
+	Name               string
+	Type               string   // http, graphql
+	Seq                int      // meta.seq
+	Tags               []string // meta.tags
+	Url                string
+	Method             string
+	Headers            []KeyValue
+	Query              []KeyValue // params:query
+	Params             []KeyValue // params:path
+	BodyMode           string     // json, text, xml, formUrlEncoded, multipartForm, graphql, none
+	Body               string            // text body of BodyMode
+	OtherBodies        map[string]string // text body blocks BodyMode doesn't use, by block name (body:xml...)
+	GraphqlVars        string            // body:graphql:vars
+	FormUrlEncoded     []KeyValue
+	MultipartForm      []KeyValue
+	Vars               []KeyValue // vars:pre-request and vars:post-response
+	PostResponseVars   []KeyValue // vars:post-response entries, also listed in Vars
+	Docs               string
+	PreRequestScript   string // script:pre-request
+	PostResponseScript string // script:post-response
+	Tests              string // tests
+	Asserts            []KeyValue
+	Auth               map[string]string
+	Settings           []KeyValue // settings block: encodeUrl, timeout, followRedirects, maxRedirects
+	Examples           []BruExample
 }
 
 type BruExample struct {
@@ -21,10 +41,12 @@ type BruExample struct {
 }
 
 type BruRequest struct {
-	Method  string
-	Url     string
-	Headers []KeyValue
-	Body    string
+	Method   string
+	Url      string
+	Headers  []KeyValue
+	Query    []KeyValue
+	BodyMode string // json, text, xml, none...
+	Body     string
 }
 
 type BruResponse struct {
@@ -34,17 +56,58 @@ type BruResponse struct {
 	Body       string
 }
 
+// BruEnvironment represents the parsed content of an environments/<name>.bru file
+type BruEnvironment struct {
+	Name    string
+	Vars    []KeyValue
+	Secrets []KeyValue // vars:secret, only Key and Enabled are set
+}
+
 type KeyValue struct {
 	Key     string
 	Value   string
 	Enabled bool
 }
 
+// Kinds of BruBlock
+const (
+	DictBlock  = "dict"  // "key: value" entries
+	TextBlock  = "text"  // free text: bodies, scripts, docs
+	ArrayBlock = "array" // list of names: vars:secret [a, b]
+)
+
+// BruDocument is the syntax tree of a .bru file: its top-level blocks in file order
+type BruDocument struct {
+	Blocks []*BruBlock `json:"blocks"`
+}
+
+// BruBlock is a block of a .bru file
+type BruBlock struct {
+	Name    string     `json:"name"`
+	Kind    string     `json:"kind"`
+	Line    int        `json:"line"`
+	Entries []BruEntry `json:"entries,omitempty"` // dict blocks
+	Text    string     `json:"text,omitempty"`    // text blocks, without the block indentation
+	Items   []BruEntry `json:"items,omitempty"`   // array blocks (Key and Enabled)
+}
+
+// BruEntry is an entry of a dictionary block or an item of an array block.
+// Entries of example blocks can hold a nested block ("request: {").
+type BruEntry struct {
+	Key     string    `json:"key"`
+	Value   string    `json:"value,omitempty"`
+	Enabled bool      `json:"enabled"`
+	Line    int       `json:"line"`
+	Block   *BruBlock `json:"block,omitempty"`
+}
+
 // PostmanCollection represents the root of the JSON
 type PostmanCollection struct {
-	Info     Info       `json:"info"`
-	Item     []Item     `json:"item"`
-	Variable []Variable `json:"variable,omitempty"`
+	Info     Info         `json:"info"`
+	Item     []Item       `json:"item"`
+	Variable []Variable   `json:"variable,omitempty"`
+	Auth     *PostmanAuth `json:"auth,omitempty"`
+	Event    []Event      `json:"event,omitempty"`
 }
 
 type Info struct {
@@ -61,11 +124,27 @@ type Item struct {
 	Request                 *Request                 `json:"request,omitempty"`                 // If it's an endpoint
 	Response                []PostmanResponse        `json:"response,omitempty"`                // Examples
 	Variable                []Variable               `json:"variable,omitempty"`                // Folder variables
+	Event                   []Event                  `json:"event,omitempty"`                   // Scripts
+	Auth                    *PostmanAuth             `json:"auth,omitempty"`                    // Folder auth
 	ProtocolProfileBehavior *ProtocolProfileBehavior `json:"protocolProfileBehavior,omitempty"` // Protocol behavior
 }
 
+// Event is a script that Postman runs before (prerequest) or after (test) a request
+type Event struct {
+	Listen string `json:"listen"`
+	Script Script `json:"script"`
+}
+
+type Script struct {
+	Type string   `json:"type"` // text/javascript
+	Exec []string `json:"exec"`
+}
+
 type ProtocolProfileBehavior struct {
-	DisableBodyPruning bool `json:"disableBodyPruning,omitempty"`
+	DisableBodyPruning bool  `json:"disableBodyPruning,omitempty"`
+	DisableUrlEncoding bool  `json:"disableUrlEncoding,omitempty"`
+	FollowRedirects    *bool `json:"followRedirects,omitempty"`
+	MaxRedirects       *int  `json:"maxRedirects,omitempty"`
 }
 
 type PostmanResponse struct {
@@ -92,7 +171,11 @@ type PostmanAuth struct {
 	Type   string        `json:"type"`
 	Bearer []AuthElement `json:"bearer,omitempty"`
 	Basic  []AuthElement `json:"basic,omitempty"`
-	// Add other types as needed
+	Apikey []AuthElement `json:"apikey,omitempty"`
+	Digest []AuthElement `json:"digest,omitempty"`
+	Oauth2 []AuthElement `json:"oauth2,omitempty"`
+	Awsv4  []AuthElement `json:"awsv4,omitempty"`
+	Ntlm   []AuthElement `json:"ntlm,omitempty"`
 }
 
 type AuthElement struct {
@@ -106,12 +189,31 @@ type Header struct {
 	Value       string `json:"value"`
 	Description string `json:"description,omitempty"`
 	Type        string `json:"type,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
 }
 
 type Body struct {
-	Mode    string                 `json:"mode"`
-	Raw     string                 `json:"raw,omitempty"`
-	Options map[string]interface{} `json:"options,omitempty"`
+	Mode       string                 `json:"mode"`
+	Raw        string                 `json:"raw,omitempty"`
+	URLEncoded []FormParam            `json:"urlencoded,omitempty"`
+	FormData   []FormParam            `json:"formdata,omitempty"`
+	GraphQL    *GraphQLBody           `json:"graphql,omitempty"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+// GraphQLBody is the body of a graphql mode request
+type GraphQLBody struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+// FormParam is an entry of an urlencoded or formdata body
+type FormParam struct {
+	Key      string      `json:"key"`
+	Value    string      `json:"value,omitempty"`
+	Src      interface{} `json:"src,omitempty"`  // File path (string) or paths ([]string) for file fields
+	Type     string      `json:"type,omitempty"` // text, file
+	Disabled bool        `json:"disabled,omitempty"`
 }
 
 // Url in Postman can be a string or an object, object is better for variables
@@ -136,7 +238,253 @@ type Variable struct {
 	Value string `json:"value"`
 }
 
+// PostmanEnvironment represents a *.postman_environment.json file
+type PostmanEnvironment struct {
+	Name       string             `json:"name"`
+	Values     []EnvironmentValue `json:"values"`
+	Scope      string             `json:"_postman_variable_scope"` // Use: "environment"
+	ExportedAt string             `json:"_postman_exported_at,omitempty"`
+	ExportedBy string             `json:"_postman_exported_using,omitempty"`
+}
+
+type EnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"` // default, secret
+	Enabled bool   `json:"enabled"`
+}
+
 type BrunoConfig struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
+	Version string   `json:"version,omitempty"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Ignore  []string `json:"ignore,omitempty"`
+}
+
+// UnmarshalJSON accepts both forms of a Postman url: a plain string or an object
+func (u *Url) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*u = parseUrl(raw)
+		return nil
+	}
+	type plainUrl Url
+	var v plainUrl
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*u = Url(v)
+	return nil
+}
+
+// UnmarshalJSON accepts non-string auth values (Postman exports booleans for some oauth2 settings)
+func (a *AuthElement) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Key   string      `json:"key"`
+		Value interface{} `json:"value"`
+		Type  string      `json:"type"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*a = AuthElement{Key: v.Key, Value: scalarString(v.Value), Type: v.Type}
+	return nil
+}
+
+// UnmarshalJSON accepts non-string variable values (number and boolean variables)
+func (va *Variable) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Key   string      `json:"key"`
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*va = Variable{Key: v.Key, Value: scalarString(v.Value)}
+	return nil
+}
+
+func scalarString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// OpenAPI represents the root of an OpenAPI 3.1 document
+type OpenAPI struct {
+	OpenAPI    string                                  `json:"openapi"` // Use: "3.1.0"
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+	Tags       []OpenAPITag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"` // path -> method -> operation
+	Components *OpenAPIComponents                      `json:"components,omitempty"`
+	Security   []map[string][]string                   `json:"security,omitempty"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	Url       string                           `json:"url"`
+	Variables map[string]OpenAPIServerVariable `json:"variables,omitempty"`
+}
+
+type OpenAPIServerVariable struct {
+	Default string `json:"default"`
+}
+
+type OpenAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	Tags        []string                    `json:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	OperationID string                      `json:"operationId,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"` // path, query, header
+	Required    bool           `json:"required,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Schema      *OpenAPISchema `json:"schema,omitempty"`
+	Example     interface{}    `json:"example,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Description string                      `json:"description,omitempty"`
+	Content     map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema   *OpenAPISchema            `json:"schema,omitempty"`
+	Example  interface{}               `json:"example,omitempty"`
+	Examples map[string]OpenAPIExample `json:"examples,omitempty"`
+}
+
+type OpenAPIExample struct {
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+type OpenAPISchema struct {
+	Type       string                    `json:"type,omitempty"`
+	Format     string                    `json:"format,omitempty"`
+	Properties map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Items      *OpenAPISchema            `json:"items,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+	Enum       []interface{}             `json:"enum,omitempty"`
+	Default    interface{}               `json:"default,omitempty"`
+	Example    interface{}               `json:"example,omitempty"`
+	AllOf      []*OpenAPISchema          `json:"allOf,omitempty"`
+	OneOf      []*OpenAPISchema          `json:"oneOf,omitempty"`
+	AnyOf      []*OpenAPISchema          `json:"anyOf,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	SecuritySchemes map[string]*OpenAPISecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type OpenAPISecurityScheme struct {
+	Type         string             `json:"type"` // http, apiKey, oauth2, openIdConnect
+	Scheme       string             `json:"scheme,omitempty"`
+	BearerFormat string             `json:"bearerFormat,omitempty"`
+	Name         string             `json:"name,omitempty"`
+	In           string             `json:"in,omitempty"` // header, query, cookie
+	Flows        *OpenAPIOAuthFlows `json:"flows,omitempty"`
+}
+
+type OpenAPIOAuthFlows struct {
+	ClientCredentials *OpenAPIOAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OpenAPIOAuthFlow `json:"authorizationCode,omitempty"`
+	Password          *OpenAPIOAuthFlow `json:"password,omitempty"`
+	Implicit          *OpenAPIOAuthFlow `json:"implicit,omitempty"`
+}
+
+type OpenAPIOAuthFlow struct {
+	AuthorizationUrl string            `json:"authorizationUrl,omitempty"`
+	TokenUrl         string            `json:"tokenUrl,omitempty"`
+	RefreshUrl       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// SarifLog represents a SARIF 2.1.0 log, as written by "bru-ship lint -format sarif"
+type SarifLog struct {
+	Version string     `json:"version"` // Use: "2.1.0"
+	Schema  string     `json:"$schema"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationUri string      `json:"informationUri,omitempty"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     SarifMessage       `json:"shortDescription"`
+	DefaultConfiguration SarifConfiguration `json:"defaultConfiguration"`
+}
+
+type SarifConfiguration struct {
+	Level string `json:"level"` // error, warning, note, none
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   SarifMessage    `json:"message"`
+	Locations []SarifLocation `json:"locations"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           *SarifRegion          `json:"region,omitempty"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SarifRegion struct {
+	StartLine int `json:"startLine"`
 }
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Headers that OpenAPI describes elsewhere (content types, security schemes)
+var openAPIReservedHeaders = map[string]bool{
+	"accept":        true,
+	"content-type":  true,
+	"authorization": true,
+}
+
+var variableReference = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// PostmanToOpenAPI builds an OpenAPI 3.1 document from a converted collection.
+// Folders become tags, request URLs become paths (":id" -> "{id}") and saved
+// examples become documented responses. The collection should be converted
+// with KeepFolders so that folder names are available for the tags.
+func PostmanToOpenAPI(collection *PostmanCollection) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: "3.1.0",
+		Info: OpenAPIInfo{
+			Title:   collection.Info.Name,
+			Version: "1.0.0",
+		},
+		Paths: make(map[string]map[string]*OpenAPIOperation),
+	}
+
+	b := &openAPIBuilder{
+		doc:          doc,
+		variables:    make(map[string]string),
+		servers:      make(map[string]bool),
+		tags:         make(map[string]bool),
+		operationIDs: make(map[string]bool),
+		schemes:      make(map[string]string),
+	}
+	for _, v := range collection.Variable {
+		b.variables[v.Key] = v.Value
+	}
+	b.addItems(collection.Item, "")
+	return doc
+}
+
+type openAPIBuilder struct {
+	doc          *OpenAPI
+	variables    map[string]string // collection variables, used as server variable defaults
+	servers      map[string]bool
+	tags         map[string]bool
+	operationIDs map[string]bool
+	schemes      map[string]string // security scheme signature -> name
+}
+
+func (b *openAPIBuilder) addItems(items []Item, tag string) {
+	for _, item := range items {
+		if item.Request == nil {
+			if !b.tags[item.Name] {
+				b.tags[item.Name] = true
+				b.doc.Tags = append(b.doc.Tags, OpenAPITag{Name: item.Name, Description: item.Description})
+			}
+			b.addItems(item.Item, item.Name)
+			continue
+		}
+		b.addOperation(item, tag)
+	}
+}
+
+func (b *openAPIBuilder) addOperation(item Item, tag string) {
+	req := item.Request
+	server, path, pathParams := openAPIPath(req.Url)
+	b.addServer(server)
+
+	method := strings.ToLower(req.Method)
+	if b.doc.Paths[path] == nil {
+		b.doc.Paths[path] = make(map[string]*OpenAPIOperation)
+	}
+	if _, exists := b.doc.Paths[path][method]; exists {
+		fmt.Printf("Warning: %s: %s %s is already documented by another request, skipped\n", item.Name, req.Method, path)
+		return
+	}
+
+	op := &OpenAPIOperation{
+		Summary:     item.Name,
+		Description: req.Description,
+		OperationID: b.operationID(item.Name),
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+	if tag != "" {
+		op.Tags = []string{tag}
+	}
+
+	// Parameters
+	pathValues := make(map[string]string)
+	for _, v := range req.Url.Variable {
+		pathValues[v.Key] = v.Value
+	}
+	for _, name := range pathParams {
+		param := OpenAPIParameter{Name: name, In: "path", Required: true, Schema: &OpenAPISchema{Type: "string"}}
+		if value := pathValues[name]; value != "" && !variableReference.MatchString(value) {
+			param.Example = value
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+	for _, q := range req.Url.Query {
+		param := OpenAPIParameter{Name: q.Key, In: "query", Schema: &OpenAPISchema{Type: "string"}}
+		if q.Value != "" && !variableReference.MatchString(q.Value) {
+			param.Example = q.Value
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+	contentType := ""
+	for _, h := range req.Header {
+		if strings.EqualFold(h.Key, "Content-Type") {
+			contentType = strings.TrimSpace(strings.SplitN(h.Value, ";", 2)[0])
+		}
+		if openAPIReservedHeaders[strings.ToLower(h.Key)] {
+			continue
+		}
+		op.Parameters = append(op.Parameters, OpenAPIParameter{Name: h.Key, In: "header", Schema: &OpenAPISchema{Type: "string"}})
+	}
+
+	op.RequestBody = openAPIRequestBody(req.Body, contentType)
+
+	// Responses from the saved examples
+	for _, resp := range item.Response {
+		code := "default"
+		if resp.Code > 0 {
+			code = fmt.Sprint(resp.Code)
+		}
+		response := op.Responses[code]
+		if response == nil {
+			description := resp.Status
+			if description == "" {
+				description = resp.Name
+			}
+			response = &OpenAPIResponse{Description: description}
+			op.Responses[code] = response
+		}
+		if resp.Body == "" {
+			continue
+		}
+		if response.Content == nil {
+			response.Content = make(map[string]OpenAPIMediaType)
+		}
+		mediaType, media := openAPIMediaType(resp.Body)
+		if existing, ok := response.Content[mediaType]; ok {
+			// Several examples for the same status code
+			if existing.Examples == nil {
+				existing.Examples = make(map[string]OpenAPIExample)
+			}
+			existing.Examples[resp.Name] = OpenAPIExample{Value: media.Example}
+			response.Content[mediaType] = existing
+			continue
+		}
+		response.Content[mediaType] = media
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &OpenAPIResponse{Description: "Default response"}
+	}
+
+	// Security, requests without auth (noauth) don't list any requirement
+	if req.Auth != nil && req.Auth.Type != "noauth" {
+		if name := b.securityScheme(item.Name, req.Auth); name != "" {
+			op.Security = []map[string][]string{{name: openAPIScopes(req.Auth)}}
+		}
+	}
+
+	b.doc.Paths[path][method] = op
+}
+
+// addServer registers the server part of a request URL, turning {{var}}
+// into server variables with the collection value as default
+func (b *openAPIBuilder) addServer(server string) {
+	if server == "" || b.servers[server] {
+		return
+	}
+	b.servers[server] = true
+
+	s := OpenAPIServer{Url: variableReference.ReplaceAllString(server, "{$1}")}
+	for _, match := range variableReference.FindAllStringSubmatch(server, -1) {
+		if s.Variables == nil {
+			s.Variables = make(map[string]OpenAPIServerVariable)
+		}
+		s.Variables[match[1]] = OpenAPIServerVariable{Default: b.variables[match[1]]}
+	}
+	b.doc.Servers = append(b.doc.Servers, s)
+}
+
+// operationID derives a unique camelCase operationId from the request name
+func (b *openAPIBuilder) operationID(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for i, w := range words {
+		if i == 0 {
+			sb.WriteString(strings.ToLower(w[:1]) + w[1:])
+		} else {
+			sb.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	id := sb.String()
+	if id == "" {
+		id = "operation"
+	}
+	unique := id
+	for i := 2; b.operationIDs[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", id, i)
+	}
+	b.operationIDs[unique] = true
+	return unique
+}
+
+// securityScheme registers the security scheme of a resolved auth and returns its name
+func (b *openAPIBuilder) securityScheme(name string, auth *PostmanAuth) string {
+	var scheme *OpenAPISecurityScheme
+	schemeName := ""
+	switch auth.Type {
+	case "bearer":
+		scheme = &OpenAPISecurityScheme{Type: "http", Scheme: "bearer"}
+		schemeName = "bearerAuth"
+	case "basic":
+		scheme = &OpenAPISecurityScheme{Type: "http", Scheme: "basic"}
+		schemeName = "basicAuth"
+	case "digest":
+		scheme = &OpenAPISecurityScheme{Type: "http", Scheme: "digest"}
+		schemeName = "digestAuth"
+	case "apikey":
+		values := authElementValues(auth.Apikey)
+		in := "header"
+		if values["in"] == "query" {
+			in = "query"
+		}
+		scheme = &OpenAPISecurityScheme{Type: "apiKey", Name: values["key"], In: in}
+		schemeName = "apiKeyAuth"
+	case "oauth2":
+		values := authElementValues(auth.Oauth2)
+		flow := &OpenAPIOAuthFlow{Scopes: make(map[string]string)}
+		for _, scope := range openAPIScopes(auth) {
+			flow.Scopes[scope] = ""
+		}
+		flows := &OpenAPIOAuthFlows{}
+		switch values["grant_type"] {
+		case "client_credentials":
+			flow.TokenUrl = values["accessTokenUrl"]
+			flows.ClientCredentials = flow
+		case "password_credentials":
+			flow.TokenUrl = values["accessTokenUrl"]
+			flows.Password = flow
+		case "implicit":
+			flow.AuthorizationUrl = values["authUrl"]
+			flows.Implicit = flow
+		default:
+			flow.AuthorizationUrl = values["authUrl"]
+			flow.TokenUrl = values["accessTokenUrl"]
+			flow.RefreshUrl = values["refreshTokenUrl"]
+			flows.AuthorizationCode = flow
+		}
+		scheme = &OpenAPISecurityScheme{Type: "oauth2", Flows: flows}
+		schemeName = "oauth2Auth"
+	default:
+		fmt.Printf("Warning: %s: auth type '%s' has no OpenAPI security scheme\n", name, auth.Type)
+		return ""
+	}
+
+	// Reuse identical schemes, number the different ones of the same type
+	signature, _ := json.Marshal(scheme)
+	if existing, ok := b.schemes[string(signature)]; ok {
+		return existing
+	}
+	if b.doc.Components == nil {
+		b.doc.Components = &OpenAPIComponents{SecuritySchemes: make(map[string]*OpenAPISecurityScheme)}
+	}
+	unique := schemeName
+	for i := 2; b.doc.Components.SecuritySchemes[unique] != nil; i++ {
+		unique = fmt.Sprintf("%s%d", schemeName, i)
+	}
+	b.doc.Components.SecuritySchemes[unique] = scheme
+	b.schemes[string(signature)] = unique
+	return unique
+}
+
+// openAPIScopes returns the oauth2 scopes required by an auth
+func openAPIScopes(auth *PostmanAuth) []string {
+	scopes := []string{}
+	if auth.Type == "oauth2" {
+		scopes = append(scopes, strings.Fields(authElementValues(auth.Oauth2)["scope"])...)
+	}
+	return scopes
+}
+
+// openAPIPath splits a request URL into its server part and an OpenAPI path.
+// Path params (":id" and "{{id}}" segments) become "{id}".
+func openAPIPath(u Url) (string, string, []string) {
+	server := ""
+	if len(u.Host) > 0 {
+		server = strings.Join(u.Host, ".")
+		if u.Protocol != "" {
+			server = u.Protocol + "://" + server
+		}
+	}
+
+	var segments []string
+	var params []string
+	for _, segment := range u.Path {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, ":") && len(segment) > 1 {
+			name := strings.TrimPrefix(segment, ":")
+			segment = "{" + name + "}"
+			params = append(params, name)
+		} else if match := variableReference.FindStringSubmatch(segment); match != nil && match[0] == segment {
+			segment = "{" + match[1] + "}"
+			params = append(params, match[1])
+		}
+		segments = append(segments, segment)
+	}
+	return server, "/" + strings.Join(segments, "/"), params
+}
+
+// openAPIRequestBody describes a Postman request body with an inferred schema
+func openAPIRequestBody(body *Body, contentType string) *OpenAPIRequestBody {
+	if body == nil {
+		return nil
+	}
+
+	switch body.Mode {
+	case "urlencoded", "formdata":
+		params := body.URLEncoded
+		mediaType := "application/x-www-form-urlencoded"
+		if body.Mode == "formdata" {
+			params = body.FormData
+			mediaType = "multipart/form-data"
+		}
+		schema := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+		for _, p := range params {
+			if p.Type == "file" {
+				schema.Properties[p.Key] = &OpenAPISchema{Type: "string", Format: "binary"}
+			} else {
+				schema.Properties[p.Key] = &OpenAPISchema{Type: "string"}
+			}
+		}
+		return &OpenAPIRequestBody{Content: map[string]OpenAPIMediaType{mediaType: {Schema: schema}}}
+	case "graphql":
+		schema := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{
+			"query":     {Type: "string"},
+			"variables": {Type: "object"},
+		}}
+		return &OpenAPIRequestBody{Content: map[string]OpenAPIMediaType{"application/json": {Schema: schema}}}
+	case "raw":
+		if body.Raw == "" {
+			return nil
+		}
+		mediaType, media := openAPIMediaType(body.Raw)
+		if contentType != "" {
+			mediaType = contentType
+		}
+		return &OpenAPIRequestBody{Content: map[string]OpenAPIMediaType{mediaType: media}}
+	}
+	return nil
+}
+
+// openAPIMediaType describes a body: JSON bodies get an inferred schema and
+// an example, anything else is documented as text
+func openAPIMediaType(body string) (string, OpenAPIMediaType) {
+	if value, ok := parseJSONBody(body); ok {
+		return "application/json", OpenAPIMediaType{Schema: InferSchema(value), Example: value}
+	}
+	return "text/plain", OpenAPIMediaType{Schema: &OpenAPISchema{Type: "string"}, Example: body}
+}
+
+// parseJSONBody parses a JSON body, quoting bare {{var}} placeholders first
+func parseJSONBody(body string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(body)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(quoteVariables(trimmed)), &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// quoteVariables wraps the {{var}} placeholders used as bare JSON values in
+// quotes, so that a JSON body with placeholders can be decoded
+func quoteVariables(body string) string {
+	var sb strings.Builder
+	inString := false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case inString && c == '\\' && i+1 < len(body):
+			sb.WriteByte(c)
+			i++
+			c = body[i]
+		case c == '"':
+			inString = !inString
+		case !inString && c == '{' && strings.HasPrefix(body[i:], "{{"):
+			if loc := variableReference.FindStringIndex(body[i:]); loc != nil && loc[0] == 0 {
+				sb.WriteString(`"` + body[i:i+loc[1]] + `"`)
+				i += loc[1] - 1
+				continue
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// InferSchema infers a JSON schema from a decoded JSON value
+func InferSchema(value interface{}) *OpenAPISchema {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		schema := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			schema.Properties[k] = InferSchema(v[k])
+		}
+		return schema
+	case []interface{}:
+		schema := &OpenAPISchema{Type: "array"}
+		if len(v) > 0 {
+			schema.Items = InferSchema(v[0])
+		}
+		return schema
+	case string:
+		return &OpenAPISchema{Type: "string"}
+	case float64:
+		if v == float64(int64(v)) {
+			return &OpenAPISchema{Type: "integer"}
+		}
+		return &OpenAPISchema{Type: "number"}
+	case bool:
+		return &OpenAPISchema{Type: "boolean"}
+	default:
+		return &OpenAPISchema{Type: "null"}
+	}
+}
@@ -0,0 +1,934 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errNotOpenAPI is returned by LoadOpenAPI for documents without an "openapi" or "swagger" version
+var errNotOpenAPI = errors.New("not an OpenAPI or Swagger document")
+
+// openAPIMethods lists the operations of a path item, in the order they are imported
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+var openAPIPathParam = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// LoadOpenAPI reads an OpenAPI 3.x or Swagger 2.0 document, in JSON or YAML.
+// Local $refs are inlined, path-level parameters are copied to each operation
+// and Swagger 2.0 documents are upgraded to the OpenAPI 3 layout.
+func LoadOpenAPI(content []byte) (*OpenAPI, error) {
+	var raw interface{}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		if err := yaml.Unmarshal(content, &raw); err != nil {
+			return nil, err
+		}
+		raw = normalizeYAML(raw)
+	}
+
+	root, ok := raw.(map[string]interface{})
+	if !ok || (root["openapi"] == nil && root["swagger"] == nil) {
+		return nil, errNotOpenAPI
+	}
+
+	root = resolveRefs(root, root, map[string]bool{}).(map[string]interface{})
+	mergePathParameters(root)
+	if root["swagger"] != nil {
+		root = swaggerToOpenAPI3(root)
+	}
+	normalizeSchemaTypes(root)
+	if info, ok := root["info"].(map[string]interface{}); ok && info["version"] != nil {
+		info["version"] = scalarString(info["version"])
+	}
+
+	content, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	var doc OpenAPI
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values produced for
+// YAML mappings with non-string keys (e.g. response codes) to JSON objects
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = normalizeYAML(val)
+		}
+		return m
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = normalizeYAML(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeYAML(val)
+		}
+		return v
+	}
+	return value
+}
+
+// resolveRefs returns a copy of value with every local "$ref" replaced by the
+// node it points to. Recursive references are cut with an empty object.
+func resolveRefs(value interface{}, root map[string]interface{}, visiting map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if visiting[ref] {
+				return map[string]interface{}{}
+			}
+			target, ok := lookupRef(root, ref)
+			if !ok {
+				fmt.Printf("Warning: unresolved reference %s\n", ref)
+				return map[string]interface{}{}
+			}
+			visiting[ref] = true
+			resolved := resolveRefs(target, root, visiting)
+			delete(visiting, ref)
+			return resolved
+		}
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = resolveRefs(val, root, visiting)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, val := range v {
+			list[i] = resolveRefs(val, root, visiting)
+		}
+		return list
+	}
+	return value
+}
+
+// lookupRef follows a local JSON pointer ("#/components/schemas/User")
+func lookupRef(root map[string]interface{}, ref string) (interface{}, bool) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	var node interface{} = root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if node, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// mergePathParameters copies the parameters declared on a path item into its
+// operations (operation parameters win) and drops the non-operation keys
+func mergePathParameters(root map[string]interface{}) {
+	paths, _ := root["paths"].(map[string]interface{})
+	for path, value := range paths {
+		item, _ := value.(map[string]interface{})
+		shared, _ := item["parameters"].([]interface{})
+
+		operations := make(map[string]interface{})
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			params, _ := op["parameters"].([]interface{})
+			declared := make(map[string]bool)
+			for _, p := range params {
+				if param, ok := p.(map[string]interface{}); ok {
+					declared[fmt.Sprint(param["in"], ":", param["name"])] = true
+				}
+			}
+			for _, p := range shared {
+				if param, ok := p.(map[string]interface{}); ok && !declared[fmt.Sprint(param["in"], ":", param["name"])] {
+					params = append(params, param)
+				}
+			}
+			if len(params) > 0 {
+				op["parameters"] = params
+			}
+			operations[method] = op
+		}
+		paths[path] = operations
+	}
+}
+
+// swaggerToOpenAPI3 upgrades a Swagger 2.0 document (with its refs already
+// inlined) to the OpenAPI 3 layout: servers, request bodies, response content
+// and security schemes
+func swaggerToOpenAPI3(root map[string]interface{}) map[string]interface{} {
+	doc := map[string]interface{}{
+		"openapi": "3.0.0",
+		"info":    root["info"],
+		"tags":    root["tags"],
+		"paths":   root["paths"],
+	}
+	if root["security"] != nil {
+		doc["security"] = root["security"]
+	}
+
+	// Servers
+	if host, _ := root["host"].(string); host != "" {
+		scheme := "https"
+		if schemes, ok := root["schemes"].([]interface{}); ok && len(schemes) > 0 {
+			scheme = scalarString(schemes[0])
+		}
+		basePath, _ := root["basePath"].(string)
+		doc["servers"] = []interface{}{map[string]interface{}{"url": scheme + "://" + host + strings.TrimSuffix(basePath, "/")}}
+	} else if basePath, _ := root["basePath"].(string); basePath != "" {
+		doc["servers"] = []interface{}{map[string]interface{}{"url": strings.TrimSuffix(basePath, "/")}}
+	}
+
+	// Security schemes
+	if definitions, ok := root["securityDefinitions"].(map[string]interface{}); ok {
+		schemes := make(map[string]interface{})
+		for name, value := range definitions {
+			def, _ := value.(map[string]interface{})
+			switch def["type"] {
+			case "basic":
+				schemes[name] = map[string]interface{}{"type": "http", "scheme": "basic"}
+			case "apiKey":
+				schemes[name] = map[string]interface{}{"type": "apiKey", "name": def["name"], "in": def["in"]}
+			case "oauth2":
+				flow := map[string]interface{}{
+					"authorizationUrl": def["authorizationUrl"],
+					"tokenUrl":         def["tokenUrl"],
+					"scopes":           def["scopes"],
+				}
+				flowName := map[string]string{
+					"implicit":    "implicit",
+					"password":    "password",
+					"application": "clientCredentials",
+					"accessCode":  "authorizationCode",
+				}[scalarString(def["flow"])]
+				schemes[name] = map[string]interface{}{"type": "oauth2", "flows": map[string]interface{}{flowName: flow}}
+			}
+		}
+		doc["components"] = map[string]interface{}{"securitySchemes": schemes}
+	}
+
+	consumes := swaggerMediaTypes(root["consumes"])
+	produces := swaggerMediaTypes(root["produces"])
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for _, value := range paths {
+		item, _ := value.(map[string]interface{})
+		for _, v := range item {
+			op, _ := v.(map[string]interface{})
+			opConsumes := consumes
+			if op["consumes"] != nil {
+				opConsumes = swaggerMediaTypes(op["consumes"])
+			}
+			opProduces := produces
+			if op["produces"] != nil {
+				opProduces = swaggerMediaTypes(op["produces"])
+			}
+			delete(op, "consumes")
+			delete(op, "produces")
+
+			// Parameters: body and formData parameters become the request body
+			var params []interface{}
+			form := map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+			formMediaType := ""
+			list, _ := op["parameters"].([]interface{})
+			for _, p := range list {
+				param, _ := p.(map[string]interface{})
+				switch param["in"] {
+				case "body":
+					mediaType := "application/json"
+					if len(opConsumes) > 0 {
+						mediaType = opConsumes[0]
+					}
+					op["requestBody"] = map[string]interface{}{
+						"description": param["description"],
+						"content":     map[string]interface{}{mediaType: map[string]interface{}{"schema": param["schema"]}},
+					}
+				case "formData":
+					schema := swaggerParamSchema(param)
+					if param["type"] == "file" {
+						schema = map[string]interface{}{"type": "string", "format": "binary"}
+						formMediaType = "multipart/form-data"
+					}
+					form["properties"].(map[string]interface{})[scalarString(param["name"])] = schema
+					if formMediaType == "" {
+						formMediaType = "application/x-www-form-urlencoded"
+						for _, mediaType := range opConsumes {
+							if mediaType == "multipart/form-data" {
+								formMediaType = mediaType
+							}
+						}
+					}
+				default:
+					converted := map[string]interface{}{
+						"name":        param["name"],
+						"in":          param["in"],
+						"required":    param["required"],
+						"description": param["description"],
+						"schema":      swaggerParamSchema(param),
+					}
+					if param["x-example"] != nil {
+						converted["example"] = param["x-example"]
+					}
+					params = append(params, converted)
+				}
+			}
+			if formMediaType != "" {
+				op["requestBody"] = map[string]interface{}{"content": map[string]interface{}{formMediaType: map[string]interface{}{"schema": form}}}
+			}
+			if len(params) > 0 {
+				op["parameters"] = params
+			} else {
+				delete(op, "parameters")
+			}
+
+			// Responses: schema and examples move under content
+			responses, _ := op["responses"].(map[string]interface{})
+			for _, r := range responses {
+				response, _ := r.(map[string]interface{})
+				if response == nil || (response["schema"] == nil && response["examples"] == nil) {
+					continue
+				}
+				content := make(map[string]interface{})
+				examples, _ := response["examples"].(map[string]interface{})
+				for mediaType, example := range examples {
+					content[mediaType] = map[string]interface{}{"schema": response["schema"], "example": example}
+				}
+				if len(content) == 0 {
+					mediaType := "application/json"
+					if len(opProduces) > 0 {
+						mediaType = opProduces[0]
+					}
+					content[mediaType] = map[string]interface{}{"schema": response["schema"]}
+				}
+				response["content"] = content
+				delete(response, "schema")
+				delete(response, "examples")
+			}
+		}
+	}
+	return doc
+}
+
+func swaggerMediaTypes(value interface{}) []string {
+	var mediaTypes []string
+	list, _ := value.([]interface{})
+	for _, v := range list {
+		mediaTypes = append(mediaTypes, scalarString(v))
+	}
+	return mediaTypes
+}
+
+// swaggerParamSchema builds the schema of a Swagger 2.0 non-body parameter
+func swaggerParamSchema(param map[string]interface{}) map[string]interface{} {
+	schema := make(map[string]interface{})
+	for _, key := range []string{"type", "format", "items", "enum", "default"} {
+		if param[key] != nil {
+			schema[key] = param[key]
+		}
+	}
+	return schema
+}
+
+// normalizeSchemaTypes turns OpenAPI 3.1 type lists (["string", "null"]) into
+// their first non-null type
+func normalizeSchemaTypes(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if types, ok := v["type"].([]interface{}); ok {
+			delete(v, "type")
+			for _, t := range types {
+				if t != "null" {
+					v["type"] = t
+					break
+				}
+			}
+		}
+		for _, val := range v {
+			normalizeSchemaTypes(val)
+		}
+	case []interface{}:
+		for _, val := range v {
+			normalizeSchemaTypes(val)
+		}
+	}
+}
+
+// ImportOpenAPI writes an OpenAPI document as a Bruno collection directory.
+// Tags become folders and operations requests. The security scheme used by
+// the whole API is set on collection.bru, requests using another scheme get
+// their own auth.
+func ImportOpenAPI(doc *OpenAPI, outputDir string, verbose bool) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+
+	name := doc.Info.Title
+	if name == "" {
+		name = "OpenAPI Collection"
+	}
+	brunoConfig := BrunoConfig{
+		Version: "1",
+		Name:    name,
+		Type:    "collection",
+		Ignore:  []string{"node_modules", ".git"},
+	}
+	if err := writeJSON(filepath.Join(outputDir, "bruno.json"), brunoConfig); err != nil {
+		return err
+	}
+
+	var schemes map[string]*OpenAPISecurityScheme
+	if doc.Components != nil {
+		schemes = doc.Components.SecuritySchemes
+	}
+	collectionScheme := defaultSecurityScheme(doc.Security, schemes)
+
+	collectionBru := &BruFile{
+		Vars: []KeyValue{{Key: "baseUrl", Value: openAPIBaseUrl(doc.Servers), Enabled: true}},
+		Docs: textBlock(doc.Info.Description),
+		Auth: map[string]string{"mode": "none"},
+	}
+	if collectionScheme != "" {
+		if auth := bruAuthFromSecurityScheme(collectionScheme, schemes[collectionScheme]); auth != nil {
+			collectionBru.Auth = auth
+		}
+	}
+	if err := WriteBruFile(filepath.Join(outputDir, "collection.bru"), collectionBru); err != nil {
+		return err
+	}
+
+	// Group the operations by their first tag, declared tags first
+	type operation struct {
+		path   string
+		method string
+		op     *OpenAPIOperation
+	}
+	var tagOrder []string
+	tagDocs := make(map[string]string)
+	grouped := make(map[string][]operation)
+	for _, tag := range doc.Tags {
+		tagOrder = append(tagOrder, tag.Name)
+		tagDocs[tag.Name] = tag.Description
+	}
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, method := range openAPIMethods {
+			op := doc.Paths[path][method]
+			if op == nil {
+				continue
+			}
+			tag := ""
+			if len(op.Tags) > 0 {
+				tag = op.Tags[0]
+				if _, ok := tagDocs[tag]; !ok {
+					tagOrder = append(tagOrder, tag)
+					tagDocs[tag] = ""
+				}
+			}
+			grouped[tag] = append(grouped[tag], operation{path: path, method: method, op: op})
+		}
+	}
+
+	writeOperations := func(dir string, operations []operation, used map[string]bool) error {
+		for i, o := range operations {
+			bru := OpenAPIOperationToBru(o.path, o.method, o.op, i+1, collectionScheme, schemes)
+			fileName := uniqueFileName(sanitizeFileName(bru.Name), used)
+			if err := WriteBruFile(filepath.Join(dir, fileName+".bru"), bru); err != nil {
+				return err
+			}
+			if verbose {
+				fmt.Printf("[OK] Imported: %s %s\n", strings.ToUpper(o.method), o.path)
+			}
+		}
+		return nil
+	}
+
+	rootNames := make(map[string]bool)
+	seq := 0
+	for _, tag := range tagOrder {
+		if len(grouped[tag]) == 0 {
+			continue
+		}
+		seq++
+		folderDir := filepath.Join(outputDir, uniqueFileName(sanitizeFileName(tag), rootNames))
+		if err := os.MkdirAll(folderDir, 0755); err != nil {
+			return err
+		}
+		folder := &BruFile{
+			Name: tag,
+			Seq:  seq,
+			Auth: map[string]string{"mode": "inherit"},
+			Docs: textBlock(tagDocs[tag]),
+		}
+		if err := WriteBruFile(filepath.Join(folderDir, "folder.bru"), folder); err != nil {
+			return err
+		}
+		if err := writeOperations(folderDir, grouped[tag], make(map[string]bool)); err != nil {
+			return err
+		}
+	}
+	return writeOperations(outputDir, grouped[""], rootNames)
+}
+
+// OpenAPIOperationToBru converts an OpenAPI operation into a BruFile request
+func OpenAPIOperationToBru(path string, method string, op *OpenAPIOperation, seq int, collectionScheme string, schemes map[string]*OpenAPISecurityScheme) *BruFile {
+	name := op.Summary
+	if name == "" {
+		name = op.OperationID
+	}
+	if name == "" {
+		name = strings.ToUpper(method) + " " + path
+	}
+
+	bru := &BruFile{
+		Name:     name,
+		Type:     "http",
+		Seq:      seq,
+		Method:   strings.ToUpper(method),
+		Headers:  []KeyValue{},
+		BodyMode: "none",
+		Auth:     openAPIOperationAuth(name, op.Security, collectionScheme, schemes),
+		Docs:     textBlock(op.Description),
+	}
+
+	// Parameters, optional query params are added disabled
+	var query []string
+	for _, p := range op.Parameters {
+		value := parameterValue(p)
+		switch p.In {
+		case "path":
+			bru.Params = append(bru.Params, KeyValue{Key: p.Name, Value: value, Enabled: true})
+		case "query":
+			bru.Query = append(bru.Query, KeyValue{Key: p.Name, Value: value, Enabled: p.Required})
+			if p.Required {
+				query = append(query, p.Name+"="+value)
+			}
+		case "header":
+			bru.Headers = append(bru.Headers, KeyValue{Key: p.Name, Value: value, Enabled: true})
+		default:
+			fmt.Printf("Warning: %s: %s parameter '%s' is not supported, skipped\n", name, p.In, p.Name)
+		}
+	}
+	bru.Url = "{{baseUrl}}" + openAPIPathParam.ReplaceAllString(path, ":$1")
+	if len(query) > 0 {
+		bru.Url += "?" + strings.Join(query, "&")
+	}
+
+	// Body
+	if op.RequestBody != nil {
+		if mediaType, ok := preferredMediaType(op.RequestBody.Content); ok {
+			media := op.RequestBody.Content[mediaType]
+			switch {
+			case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+				fields := formFields(media.Schema)
+				if mediaType == "multipart/form-data" {
+					bru.BodyMode = "multipartForm"
+					bru.MultipartForm = fields
+				} else {
+					bru.BodyMode = "formUrlEncoded"
+					bru.FormUrlEncoded = fields
+				}
+			case isJSONMediaType(mediaType):
+				bru.BodyMode = "json"
+				bru.Body = exampleText(mediaExample(media))
+			case strings.Contains(mediaType, "xml"):
+				bru.BodyMode = "xml"
+				bru.Body = exampleText(mediaExample(media))
+			default:
+				bru.BodyMode = "text"
+				bru.Body = exampleText(mediaExample(media))
+			}
+		}
+	}
+
+	// Documented responses become examples
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if _, err := strconv.Atoi(code); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		response := op.Responses[code]
+		status, _ := strconv.Atoi(code)
+		ex := BruExample{
+			Name: strings.TrimSpace(strings.SplitN(response.Description, "\n", 2)[0]),
+			Request: BruRequest{
+				Url:    bru.Url,
+				Method: method,
+			},
+			Response: BruResponse{
+				Status:     status,
+				StatusText: http.StatusText(status),
+			},
+		}
+		if ex.Name == "" {
+			ex.Name = code
+		}
+
+		mediaType, ok := preferredMediaType(response.Content)
+		if !ok {
+			bru.Examples = append(bru.Examples, ex)
+			continue
+		}
+		media := response.Content[mediaType]
+		ex.Response.Headers = []KeyValue{{Key: "Content-Type", Value: mediaType, Enabled: true}}
+		if len(media.Examples) > 0 {
+			// One example per named example
+			names := make([]string, 0, len(media.Examples))
+			for exampleName := range media.Examples {
+				names = append(names, exampleName)
+			}
+			sort.Strings(names)
+			for _, exampleName := range names {
+				named := ex
+				named.Name = ex.Name + " (" + exampleName + ")"
+				named.Response.Body = exampleText(media.Examples[exampleName].Value)
+				bru.Examples = append(bru.Examples, named)
+			}
+			continue
+		}
+		ex.Response.Body = exampleText(mediaExample(media))
+		bru.Examples = append(bru.Examples, ex)
+	}
+
+	return bru
+}
+
+// openAPIBaseUrl returns the first server URL with its variables set to their defaults
+func openAPIBaseUrl(servers []OpenAPIServer) string {
+	if len(servers) == 0 {
+		return ""
+	}
+	url := servers[0].Url
+	for name, variable := range servers[0].Variables {
+		url = strings.ReplaceAll(url, "{"+name+"}", variable.Default)
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+// defaultSecurityScheme picks the scheme set on collection.bru: the first one
+// required by the document, or the first declared one
+func defaultSecurityScheme(security []map[string][]string, schemes map[string]*OpenAPISecurityScheme) string {
+	if name := firstSecurityRequirement(security); name != "" {
+		return name
+	}
+	if security != nil {
+		// Documents with "security: []" are public
+		return ""
+	}
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+func firstSecurityRequirement(security []map[string][]string) string {
+	for _, requirement := range security {
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) > 0 {
+			return names[0]
+		}
+	}
+	return ""
+}
+
+// openAPIOperationAuth returns the auth of a request: inherited from
+// collection.bru unless the operation uses another scheme or no auth at all
+func openAPIOperationAuth(name string, security []map[string][]string, collectionScheme string, schemes map[string]*OpenAPISecurityScheme) map[string]string {
+	if security == nil {
+		return map[string]string{"mode": "inherit"}
+	}
+	scheme := firstSecurityRequirement(security)
+	if scheme == "" {
+		return map[string]string{"mode": "none"}
+	}
+	if scheme == collectionScheme {
+		return map[string]string{"mode": "inherit"}
+	}
+	if auth := bruAuthFromSecurityScheme(name, schemes[scheme]); auth != nil {
+		return auth
+	}
+	return map[string]string{"mode": "inherit"}
+}
+
+// bruAuthFromSecurityScheme maps a security scheme to a Bruno auth map.
+// Credentials are left as {{variables}} to be set in an environment.
+func bruAuthFromSecurityScheme(name string, scheme *OpenAPISecurityScheme) map[string]string {
+	if scheme == nil {
+		fmt.Printf("Warning: %s: undefined security scheme\n", name)
+		return nil
+	}
+
+	switch scheme.Type {
+	case "http":
+		switch strings.ToLower(scheme.Scheme) {
+		case "bearer":
+			return map[string]string{"mode": "bearer", "token": "{{token}}"}
+		case "basic":
+			return map[string]string{"mode": "basic", "username": "{{username}}", "password": "{{password}}"}
+		case "digest":
+			return map[string]string{"mode": "digest", "username": "{{username}}", "password": "{{password}}"}
+		}
+	case "apiKey":
+		switch scheme.In {
+		case "header":
+			return map[string]string{"mode": "apikey", "key": scheme.Name, "value": "{{apiKey}}", "placement": "header"}
+		case "query":
+			return map[string]string{"mode": "apikey", "key": scheme.Name, "value": "{{apiKey}}", "placement": "queryparams"}
+		}
+	case "oauth2":
+		if scheme.Flows == nil {
+			break
+		}
+		auth := map[string]string{"mode": "oauth2", "client_id": "{{clientId}}", "client_secret": "{{clientSecret}}"}
+		var flow *OpenAPIOAuthFlow
+		switch {
+		case scheme.Flows.ClientCredentials != nil:
+			flow = scheme.Flows.ClientCredentials
+			auth["grant_type"] = "client_credentials"
+		case scheme.Flows.Password != nil:
+			flow = scheme.Flows.Password
+			auth["grant_type"] = "password"
+			auth["username"] = "{{username}}"
+			auth["password"] = "{{password}}"
+		case scheme.Flows.AuthorizationCode != nil:
+			flow = scheme.Flows.AuthorizationCode
+			auth["grant_type"] = "authorization_code"
+			auth["callback_url"] = "{{callbackUrl}}"
+		case scheme.Flows.Implicit != nil:
+			flow = scheme.Flows.Implicit
+			auth["grant_type"] = "implicit"
+			auth["callback_url"] = "{{callbackUrl}}"
+		default:
+			return nil
+		}
+		if flow.AuthorizationUrl != "" {
+			auth["authorization_url"] = flow.AuthorizationUrl
+		}
+		if flow.TokenUrl != "" {
+			auth["access_token_url"] = flow.TokenUrl
+		}
+		if flow.RefreshUrl != "" {
+			auth["refresh_token_url"] = flow.RefreshUrl
+		}
+		scopes := make([]string, 0, len(flow.Scopes))
+		for scope := range flow.Scopes {
+			scopes = append(scopes, scope)
+		}
+		sort.Strings(scopes)
+		auth["scope"] = strings.Join(scopes, " ")
+		return auth
+	}
+
+	fmt.Printf("Warning: %s: security scheme '%s' has no Bruno auth mode\n", name, scheme.Type)
+	return nil
+}
+
+// parameterValue returns an example value for a parameter, empty if the spec has none
+func parameterValue(p OpenAPIParameter) string {
+	if p.Example != nil {
+		return scalarString(p.Example)
+	}
+	if p.Schema != nil {
+		for _, v := range []interface{}{p.Schema.Example, p.Schema.Default} {
+			if v != nil {
+				return scalarString(v)
+			}
+		}
+		if len(p.Schema.Enum) > 0 {
+			return scalarString(p.Schema.Enum[0])
+		}
+	}
+	return ""
+}
+
+// preferredMediaType picks the media type to import: JSON first, then forms, XML and text
+func preferredMediaType(content map[string]OpenAPIMediaType) (string, bool) {
+	if len(content) == 0 {
+		return "", false
+	}
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, match := range []func(string) bool{
+		isJSONMediaType,
+		func(m string) bool { return m == "application/x-www-form-urlencoded" },
+		func(m string) bool { return m == "multipart/form-data" },
+		func(m string) bool { return strings.Contains(m, "xml") },
+		func(m string) bool { return strings.HasPrefix(m, "text/") },
+	} {
+		for _, mediaType := range mediaTypes {
+			if match(mediaType) {
+				return mediaType, true
+			}
+		}
+	}
+	return mediaTypes[0], true
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// mediaExample returns the example of a media type, generated from the schema if there is none
+func mediaExample(media OpenAPIMediaType) interface{} {
+	if media.Example != nil {
+		return media.Example
+	}
+	return SchemaExample(media.Schema)
+}
+
+// formFields lists the properties of a form schema as Bruno form fields.
+// Binary properties become @file() fields.
+func formFields(schema *OpenAPISchema) []KeyValue {
+	if schema == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(schema.Properties))
+	for key := range schema.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fields []KeyValue
+	for _, key := range keys {
+		property := schema.Properties[key]
+		value := ""
+		if property.Format == "binary" {
+			value = "@file()"
+		} else if example := SchemaExample(property); example != nil {
+			value = scalarString(example)
+		}
+		fields = append(fields, KeyValue{Key: key, Value: value, Enabled: true})
+	}
+	return fields
+}
+
+// exampleText renders an example value as body text: strings as they are,
+// anything else as indented JSON
+func exampleText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return textBlock(v)
+	}
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return textBlock(string(content))
+}
+
+// SchemaExample generates an example value from a schema, using the
+// example, default and enum values it declares when present
+func SchemaExample(schema *OpenAPISchema) interface{} {
+	return schemaExample(schema, 0)
+}
+
+func schemaExample(schema *OpenAPISchema, depth int) interface{} {
+	if schema == nil || depth > 10 {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	if len(schema.AllOf) > 0 {
+		merged := make(map[string]interface{})
+		for _, s := range schema.AllOf {
+			if object, ok := schemaExample(s, depth+1).(map[string]interface{}); ok {
+				for k, v := range object {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	if len(schema.OneOf) > 0 {
+		return schemaExample(schema.OneOf[0], depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return schemaExample(schema.AnyOf[0], depth+1)
+	}
+
+	switch schema.Type {
+	case "array":
+		item := schemaExample(schema.Items, depth+1)
+		if item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{item}
+	case "string":
+		switch schema.Format {
+		case "date":
+			return "2024-01-01"
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+		case "uri", "url":
+			return "https://example.com"
+		case "binary", "byte":
+			return ""
+		}
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return true
+	case "object", "":
+		if len(schema.Properties) == 0 && schema.Type == "" {
+			return nil
+		}
+		object := make(map[string]interface{})
+		for key, property := range schema.Properties {
+			object[key] = schemaExample(property, depth+1)
+		}
+		return object
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportOpenAPI(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "petstore.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := LoadOpenAPI(content)
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	if spec.Info.Version != "1" {
+		t.Errorf("Expected the numeric version as a string, got %q", spec.Info.Version)
+	}
+
+	outputDir := t.TempDir()
+	if err := ImportOpenAPI(spec, outputDir, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, file := range []string{"bruno.json", "collection.bru", "Pets/folder.bru", "Pets/Get Pet.bru", "Pets/Create Pet.bru", "Pets/uploadPhoto.bru", "Health.bru"} {
+		if _, err := os.Stat(filepath.Join(outputDir, file)); err != nil {
+			t.Errorf("Expected %s to be written: %v", file, err)
+		}
+	}
+
+	collectionBru, err := ParseBruFile(filepath.Join(outputDir, "collection.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collectionBru.Auth["mode"] != "bearer" || collectionBru.Auth["token"] != "{{token}}" {
+		t.Errorf("Unexpected collection auth: %v", collectionBru.Auth)
+	}
+	if len(collectionBru.Vars) != 1 || collectionBru.Vars[0].Value != "https://api.petstore.example.com/v1" {
+		t.Errorf("Unexpected collection vars: %+v", collectionBru.Vars)
+	}
+
+	get, err := ParseBruFile(filepath.Join(outputDir, "Pets", "Get Pet.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if get.Url != "{{baseUrl}}/pets/:petId" || get.Method != "GET" || get.Auth["mode"] != "inherit" {
+		t.Errorf("Unexpected request: %+v", get)
+	}
+	if len(get.Params) != 1 || get.Params[0].Key != "petId" || get.Params[0].Value != "7" {
+		t.Errorf("Expected the path-level petId param, got %+v", get.Params)
+	}
+	if len(get.Query) != 1 || get.Query[0].Key != "fields" || get.Query[0].Enabled {
+		t.Errorf("Expected the optional fields query param disabled, got %+v", get.Query)
+	}
+	if len(get.Headers) != 1 || get.Headers[0].Key != "X-Request-Id" {
+		t.Errorf("Expected the X-Request-Id header, got %+v", get.Headers)
+	}
+	if get.Docs != "Returns a single pet\n" {
+		t.Errorf("Unexpected docs: %q", get.Docs)
+	}
+	if len(get.Examples) != 2 || get.Examples[0].Response.Status != 200 || get.Examples[1].Response.Status != 404 {
+		t.Fatalf("Expected the 200 and 404 examples, got %+v", get.Examples)
+	}
+	if body := get.Examples[0].Response.Body; !strings.Contains(body, `"name": "Rex"`) || !strings.Contains(body, `"parent": null`) {
+		t.Errorf("Expected a body generated from the schema, got %q", body)
+	}
+
+	create, err := ParseBruFile(filepath.Join(outputDir, "Pets", "Create Pet.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if create.BodyMode != "json" || !strings.Contains(create.Body, `"tags": [`) || !strings.Contains(create.Body, `"id": 0`) {
+		t.Errorf("Unexpected body: %s %q", create.BodyMode, create.Body)
+	}
+	if len(create.Examples) != 1 || create.Examples[0].Name != "Created (dog)" || !strings.Contains(create.Examples[0].Response.Body, `"id": 1`) {
+		t.Errorf("Unexpected examples: %+v", create.Examples)
+	}
+
+	upload, err := ParseBruFile(filepath.Join(outputDir, "Pets", "uploadPhoto.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload.Auth["mode"] != "apikey" || upload.Auth["key"] != "X-Api-Key" || upload.Auth["placement"] != "header" {
+		t.Errorf("Unexpected upload auth: %v", upload.Auth)
+	}
+	if upload.BodyMode != "multipartForm" || len(upload.MultipartForm) != 2 ||
+		upload.MultipartForm[0] != (KeyValue{Key: "caption", Value: "Cute", Enabled: true}) ||
+		upload.MultipartForm[1] != (KeyValue{Key: "file", Value: "@file()", Enabled: true}) {
+		t.Errorf("Unexpected multipart form: %+v", upload.MultipartForm)
+	}
+
+	health, err := ParseBruFile(filepath.Join(outputDir, "Health.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Auth["mode"] != "none" || len(health.Examples) != 1 || health.Examples[0].Response.Body != "ok\n" {
+		t.Errorf("Unexpected health request: %+v", health)
+	}
+
+	// The untagged operation is a top-level request of the collection
+	exported, err := WalkAndConvert(Config{Input: outputDir, KeepFolders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range exported.Item {
+		names = append(names, item.Name)
+	}
+	if strings.Join(names, ",") != "Pets,Health" {
+		t.Errorf("Unexpected exported items: %v", names)
+	}
+}
+
+func TestLoadOpenAPI_Swagger(t *testing.T) {
+	swagger := `{
+  "swagger": "2.0",
+  "info": {"title": "Legacy", "version": "2.1"},
+  "host": "legacy.example.com",
+  "basePath": "/api/",
+  "schemes": ["http"],
+  "securityDefinitions": {
+    "oauth": {"type": "oauth2", "flow": "application", "tokenUrl": "https://legacy.example.com/token", "scopes": {"read": "Read"}}
+  },
+  "definitions": {
+    "Login": {"type": "object", "properties": {"user": {"type": "string"}}}
+  },
+  "paths": {
+    "/login": {
+      "post": {
+        "parameters": [
+          {"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Login"}},
+          {"name": "debug", "in": "query", "type": "boolean", "required": true}
+        ],
+        "responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/Login"}}}
+      }
+    },
+    "/avatar": {
+      "post": {
+        "consumes": ["multipart/form-data"],
+        "parameters": [{"name": "image", "in": "formData", "type": "file"}],
+        "responses": {}
+      }
+    }
+  }
+}`
+	spec, err := LoadOpenAPI([]byte(swagger))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	if len(spec.Servers) != 1 || spec.Servers[0].Url != "http://legacy.example.com/api" {
+		t.Errorf("Unexpected servers: %+v", spec.Servers)
+	}
+
+	login := spec.Paths["/login"]["post"]
+	if login == nil || login.RequestBody == nil || login.RequestBody.Content["application/json"].Schema.Properties["user"] == nil {
+		t.Fatalf("Expected the body parameter as a JSON request body, got %+v", login)
+	}
+	if len(login.Parameters) != 1 || login.Parameters[0].Schema.Type != "boolean" || !login.Parameters[0].Required {
+		t.Errorf("Unexpected parameters: %+v", login.Parameters)
+	}
+	if login.Responses["200"].Content["application/json"].Schema.Type != "object" {
+		t.Errorf("Expected the response schema under content, got %+v", login.Responses["200"])
+	}
+
+	avatar := spec.Paths["/avatar"]["post"]
+	if avatar.RequestBody == nil || avatar.RequestBody.Content["multipart/form-data"].Schema.Properties["image"].Format != "binary" {
+		t.Errorf("Expected the file as a multipart field, got %+v", avatar.RequestBody)
+	}
+
+	auth := bruAuthFromSecurityScheme("oauth", spec.Components.SecuritySchemes["oauth"])
+	if auth["grant_type"] != "client_credentials" || auth["access_token_url"] != "https://legacy.example.com/token" || auth["scope"] != "read" {
+		t.Errorf("Unexpected oauth2 auth: %v", auth)
+	}
+
+	if _, err := LoadOpenAPI([]byte(`{"info": {"name": "Postman"}, "item": []}`)); err != errNotOpenAPI {
+		t.Errorf("Expected errNotOpenAPI for a Postman collection, got %v", err)
+	}
+}
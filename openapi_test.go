@@ -0,0 +1,199 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPostmanToOpenAPI(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	collectionBru := `auth {
+  mode: bearer
+}
+
+auth:bearer {
+  token: {{token}}
+}
+
+vars:pre-request {
+  baseUrl: https://api.example.com
+}
+`
+	getUser := `meta {
+  name: Get User
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/users/:id?expand=profile
+  body: none
+  auth: inherit
+}
+
+params:path {
+  id: 42
+}
+
+headers {
+  X-Trace: abc
+  Accept: application/json
+}
+
+example {
+  name: Found
+
+  request: {
+    url: {{baseUrl}}/users/42
+    method: get
+  }
+
+  response: {
+    status: {
+      code: 200
+      text: OK
+    }
+
+    body: {
+      type: json
+      content: '''
+        {"id": 42, "name": "Ada", "tags": ["admin"], "score": 1.5}
+      '''
+    }
+  }
+}
+`
+	createUser := `meta {
+  name: Create User
+  type: http
+  seq: 2
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+}
+
+body:json {
+  {"name": "{{name}}", "age": {{age}}, "active": true}
+}
+`
+	usersDir := filepath.Join(tmpDir, "Users")
+	if err := os.MkdirAll(usersDir, 0755); err != nil {
+		t.Fatalf("failed to create folder: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(tmpDir, "collection.bru"):    collectionBru,
+		filepath.Join(usersDir, "Get User.bru"):    getUser,
+		filepath.Join(usersDir, "Create User.bru"): createUser,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	collection, err := WalkAndConvert(Config{Input: tmpDir, KeepFolders: true, Title: "Users API"})
+	if err != nil {
+		t.Fatalf("WalkAndConvert returned error: %v", err)
+	}
+	doc := PostmanToOpenAPI(collection)
+
+	if doc.OpenAPI != "3.1.0" || doc.Info.Title != "Users API" {
+		t.Errorf("Unexpected document header: %s %s", doc.OpenAPI, doc.Info.Title)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].Url != "{baseUrl}" || doc.Servers[0].Variables["baseUrl"].Default != "https://api.example.com" {
+		t.Errorf("Unexpected servers: %+v", doc.Servers)
+	}
+	if len(doc.Tags) != 1 || doc.Tags[0].Name != "Users" {
+		t.Errorf("Expected the Users tag, got %+v", doc.Tags)
+	}
+
+	get := doc.Paths["/users/{id}"]["get"]
+	if get == nil {
+		t.Fatalf("Expected GET /users/{id}, got paths %v", doc.Paths)
+	}
+	if get.OperationID != "getUser" || len(get.Tags) != 1 || get.Tags[0] != "Users" {
+		t.Errorf("Unexpected operation: %s %v", get.OperationID, get.Tags)
+	}
+	params := make(map[string]OpenAPIParameter)
+	for _, p := range get.Parameters {
+		params[p.In+":"+p.Name] = p
+	}
+	if p, ok := params["path:id"]; !ok || !p.Required || p.Example != "42" {
+		t.Errorf("Unexpected path parameter: %+v", p)
+	}
+	if _, ok := params["query:expand"]; !ok {
+		t.Errorf("Expected the expand query parameter, got %+v", get.Parameters)
+	}
+	if _, ok := params["header:X-Trace"]; !ok {
+		t.Errorf("Expected the X-Trace header parameter, got %+v", get.Parameters)
+	}
+	if _, ok := params["header:Accept"]; ok {
+		t.Errorf("Accept should not be documented as a parameter")
+	}
+
+	ok := get.Responses["200"]
+	if ok == nil || ok.Description != "OK" {
+		t.Fatalf("Expected a 200 response, got %+v", get.Responses)
+	}
+	schema := ok.Content["application/json"].Schema
+	if schema == nil || schema.Type != "object" ||
+		schema.Properties["id"].Type != "integer" ||
+		schema.Properties["score"].Type != "number" ||
+		schema.Properties["tags"].Items.Type != "string" {
+		t.Errorf("Unexpected response schema: %+v", schema)
+	}
+	if len(get.Security) != 1 {
+		t.Errorf("Expected the inherited bearer auth, got %v", get.Security)
+	} else if _, ok := get.Security[0]["bearerAuth"]; !ok {
+		t.Errorf("Expected the bearerAuth scheme, got %v", get.Security)
+	}
+	if scheme := doc.Components.SecuritySchemes["bearerAuth"]; scheme == nil || scheme.Type != "http" || scheme.Scheme != "bearer" {
+		t.Errorf("Unexpected security scheme: %+v", scheme)
+	}
+
+	post := doc.Paths["/users"]["post"]
+	if post == nil || post.RequestBody == nil {
+		t.Fatalf("Expected POST /users with a request body")
+	}
+	body := post.RequestBody.Content["application/json"].Schema
+	if body == nil || body.Properties["name"].Type != "string" || body.Properties["age"].Type != "string" || body.Properties["active"].Type != "boolean" {
+		t.Errorf("Unexpected request body schema: %+v", body)
+	}
+	if len(post.Security) != 0 {
+		t.Errorf("Expected auth none to clear security, got %v", post.Security)
+	}
+	if post.Responses["default"] == nil {
+		t.Errorf("Expected a default response, got %+v", post.Responses)
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	value, ok := parseJSONBody(`[{"id": {{id}}, "nested": {"ok": null}}]`)
+	if !ok {
+		t.Fatalf("Expected the body with placeholders to parse")
+	}
+	schema := InferSchema(value)
+	if schema.Type != "array" || schema.Items.Type != "object" {
+		t.Fatalf("Unexpected schema: %+v", schema)
+	}
+	if schema.Items.Properties["nested"].Properties["ok"].Type != "null" {
+		t.Errorf("Unexpected nested schema: %+v", schema.Items.Properties["nested"])
+	}
+
+	value, ok = parseJSONBody(`{"greeting": "Hello {{name}} \"{{x}}\"", "id": {{id}}}`)
+	if !ok {
+		t.Fatalf("Expected placeholders inside strings to be kept")
+	}
+	if greeting := value.(map[string]interface{})["greeting"]; greeting != `Hello {{name}} "{{x}}"` {
+		t.Errorf("Unexpected string value: %v", greeting)
+	}
+
+	if _, ok := parseJSONBody("plain text"); ok {
+		t.Errorf("Expected plain text not to parse as JSON")
+	}
+}
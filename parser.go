@@ -1,383 +1,689 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
-// ParseBruFile parses a .bru file and returns a BruFile struct
+// ParseError is a syntax error of a .bru file
+type ParseError struct {
+	Path    string
+	Line    int // 1-based
+	Column  int // 1-based
+	Snippet string
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Message)
+	if e.Snippet != "" {
+		msg += "\n    " + e.Snippet
+	}
+	return msg
+}
+
+// ParseErrors lists the syntax errors found in one or more .bru files
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Files returns the paths of the files with errors, in order and without duplicates
+func (e ParseErrors) Files() []string {
+	var files []string
+	for _, err := range e {
+		if !contains(files, err.Path) {
+			files = append(files, err.Path)
+		}
+	}
+	return files
+}
+
+// ParseBruFile parses a .bru file and returns a BruFile struct.
+// Malformed input (unclosed blocks, stray braces, badly indented content)
+// is reported as ParseErrors, together with the partially parsed BruFile.
 func ParseBruFile(path string) (*BruFile, error) {
+	doc, err := parseBruPath(path)
+	if doc == nil {
+		return nil, err
+	}
+	return bruFileFromDocument(doc), err
+}
+
+func parseBruPath(path string) (*BruDocument, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
+	return ParseBru(file, path)
+}
 
-	bru := &BruFile{
-		Headers: []KeyValue{},
-		Vars:    []KeyValue{},
-		Auth:    make(map[string]string),
+// ParseBru parses the Bru language into its syntax tree:
+//
+//	document   = { blank | block }
+//	block      = name "{" ( dictionary | text ) "}" | name "[" array "]"
+//	dictionary = { blank | ["~"] key ":" value | key ":" "'''" lines "'''" | key ":" "{" dictionary "}" }
+//	text       = { line indented one level deeper than the block }
+//	array      = name { "," name }
+//
+// Nested "key: {" blocks are only read inside example blocks. path is only
+// used in the errors. Syntax errors are returned as ParseErrors, together
+// with the document parsed up to them.
+func ParseBru(r io.Reader, path string) (*BruDocument, error) {
+	p := &bruParser{lexer: newBruLexer(r), path: path}
+	doc := p.parseDocument()
+	if err := p.lexer.err(); err != nil {
+		return nil, err
+	}
+	if len(p.errs) > 0 {
+		return doc, p.errs
 	}
-	scanner := bufio.NewScanner(file)
+	return doc, nil
+}
 
-	var currentBlock string
-	var bodyBuffer strings.Builder
-	var docsBuffer strings.Builder
-	blockIndents := make(map[string]string)
+type bruParser struct {
+	lexer *bruLexer
+	path  string
+	errs  ParseErrors
+	// The end of the file was already reported by an unclosed multiline value
+	eofReported bool
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmedLine := strings.TrimSpace(line)
+func (p *bruParser) errorf(tok token, format string, args ...interface{}) {
+	p.errs = append(p.errs, &ParseError{Path: p.path, Line: tok.line, Column: tok.column(), Snippet: snippetOf(tok.raw), Message: fmt.Sprintf(format, args...)})
+}
 
-		// Calculate indentation (spaces/tabs before content)
-		indent := ""
-		if len(trimmedLine) > 0 {
-			indent = line[:strings.Index(line, trimmedLine)]
-		}
+// unclosed reports a top-level block left open
+func (p *bruParser) unclosed(open token) {
+	if !p.eofReported {
+		p.errorf(open, "block '%s %c' is not closed", open.name, open.delim)
+	}
+}
 
-		if trimmedLine == "" && !strings.HasPrefix(currentBlock, "body") && currentBlock != "docs" && currentBlock != "example" {
-			continue
-		}
+// startsBlock reports whether a token opens a top-level block, which
+// means the block opened at the same indentation wasn't closed
+func startsBlock(tok token, open token) bool {
+	return tok.kind == tokenOpen && tok.indent == open.indent && isKnownBlock(tok.name)
+}
 
-		// Detect block start
-		if strings.HasSuffix(trimmedLine, " {") && !strings.HasPrefix(currentBlock, "example") {
-			blockName := strings.TrimSuffix(trimmedLine, " {")
-			if blockName == "meta" || blockName == "headers" || blockName == "vars:pre-request" || blockName == "vars:post-response" || strings.HasPrefix(blockName, "body") || blockName == "docs" || strings.HasPrefix(blockName, "auth") || blockName == "example" {
-				currentBlock = blockName
-				blockIndents[currentBlock] = indent
-				if blockName == "example" {
-					// Start a new example
-					bru.Examples = append(bru.Examples, BruExample{})
-				}
-				continue
-			}
-			// HTTP Methods
-			if blockName == "get" || blockName == "post" || blockName == "put" || blockName == "delete" || blockName == "patch" || blockName == "options" || blockName == "head" {
-				bru.Method = strings.ToUpper(blockName)
-				currentBlock = "request"
-				blockIndents[currentBlock] = indent
+func (p *bruParser) parseDocument() *BruDocument {
+	doc := &BruDocument{Blocks: []*BruBlock{}}
+	for {
+		tok := p.lexer.next()
+		switch tok.kind {
+		case tokenEOF:
+			return doc
+		case tokenBlank:
+		case tokenOpen:
+			if !isBlockName(tok.name) {
+				p.errorf(tok, "unexpected text outside of a block")
 				continue
 			}
+			doc.Blocks = append(doc.Blocks, p.parseBlock(tok, nil))
+		case tokenClose:
+			p.errorf(tok, "unexpected '%c' outside of a block", tok.delim)
+		default:
+			p.errorf(tok, "unexpected text outside of a block")
 		}
+	}
+}
 
-		// Detect block end
-		if trimmedLine == "}" {
-			if strings.HasPrefix(currentBlock, "body") {
-				// For body, check if it's the closing brace of the block
-				// strict check using indentation
-				if line == blockIndents[currentBlock]+"}" {
-					bru.Body = bodyBuffer.String()
-					bodyBuffer.Reset()
-					currentBlock = ""
-					continue
-				}
-			} else if currentBlock == "docs" {
-				if line == blockIndents[currentBlock]+"}" {
-					bru.Docs = docsBuffer.String()
-					docsBuffer.Reset()
-					currentBlock = ""
-					continue
-				}
-			} else if currentBlock == "example" {
-				if line == blockIndents[currentBlock]+"}" {
-					currentBlock = ""
-					continue
-				}
-			} else if strings.HasPrefix(currentBlock, "example-") {
-				// Let switch handle nested example blocks
-			} else {
-				// For other blocks, check indentation if available
-				if val, ok := blockIndents[currentBlock]; ok {
-					if line == val+"}" {
-						currentBlock = ""
-						continue
-					}
-				} else {
-					// Fallback for blocks without stored indent (shouldn't happen for new blocks)
-					currentBlock = ""
-					continue
-				}
+// parseBlock parses the content of the block opened by open, up to its closing line
+func (p *bruParser) parseBlock(open token, parent *BruBlock) *BruBlock {
+	block := &BruBlock{Name: strings.TrimSuffix(open.name, ":"), Line: open.line}
+	switch {
+	case open.delim == '[':
+		block.Kind = ArrayBlock
+		p.parseArray(block, open)
+	case blockKind(block.Name, parent) == TextBlock:
+		block.Kind = TextBlock
+		p.parseText(block, open, parent)
+	default:
+		block.Kind = DictBlock
+		p.parseDictionary(block, open, parent)
+	}
+	return block
+}
+
+func (p *bruParser) parseDictionary(block *BruBlock, open token, parent *BruBlock) {
+	nested := parent != nil || block.Name == "example"
+	for {
+		tok := p.lexer.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			if parent == nil {
+				p.unclosed(open)
+			}
+			return
+		case tok.kind == tokenClose:
+			p.lexer.next()
+			if tok.indent != open.indent {
+				p.errorf(tok, "closing brace of block '%s {' is not aligned with its opening line %d", open.name, open.line)
 			}
+			return
+		case parent == nil && startsBlock(tok, open):
+			p.unclosed(open)
+			return
+		}
+
+		p.lexer.next()
+		switch {
+		case tok.kind == tokenBlank:
+		case tok.kind == tokenOpen && tok.delim == '{' && nested && strings.HasSuffix(tok.name, ":"):
+			child := p.parseBlock(tok, block)
+			block.Entries = append(block.Entries, BruEntry{Key: child.Name, Enabled: true, Line: tok.line, Block: child})
+		default:
+			p.parseEntry(block, tok)
 		}
+	}
+}
 
-		var idx int = -1
-		if len(bru.Examples) > 0 {
-			idx = len(bru.Examples) - 1
+// parseEntry parses a "key: value" line, and the following lines of triple-quoted multiline values
+func (p *bruParser) parseEntry(block *BruBlock, tok token) {
+	// "key: {" and "key: [a]" values outside examples are plain values
+	if tok.kind == tokenOpen {
+		if key, value, ok := strings.Cut(strings.TrimSpace(tok.raw), ":"); ok {
+			tok.kind, tok.name, tok.value = tokenPair, strings.TrimSpace(key), strings.TrimSpace(value)
 		}
+	}
+	if tok.kind != tokenPair {
+		p.errorf(tok, "expected 'key: value' in block '%s'", block.Name)
+		return
+	}
 
-		switch currentBlock {
-		case "meta":
-			parts := strings.SplitN(trimmedLine, ":", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				val := strings.TrimSpace(parts[1])
-				if key == "name" {
-					bru.Name = val
-				} else if key == "type" {
-					bru.Type = val
-				}
-			}
-		case "request":
-			parts := strings.SplitN(trimmedLine, ":", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				val := strings.TrimSpace(parts[1])
-				if key == "url" {
-					bru.Url = val
-				}
-			}
-		case "headers":
-			parts := strings.SplitN(trimmedLine, ":", 2)
-			if len(parts) == 2 {
-				bru.Headers = append(bru.Headers, KeyValue{
-					Key:     strings.TrimSpace(parts[0]),
-					Value:   strings.TrimSpace(parts[1]),
-					Enabled: true,
-				})
-			}
-		case "vars:pre-request", "vars:post-response":
-			parts := strings.SplitN(trimmedLine, ":", 2)
-			if len(parts) == 2 {
-				bru.Vars = append(bru.Vars, KeyValue{
-					Key:     strings.TrimSpace(parts[0]),
-					Value:   strings.TrimSpace(parts[1]),
-					Enabled: true,
-				})
-			}
-		case "docs":
-			docsBuffer.WriteString(line + "\n")
-		case "example":
-			if idx == -1 {
-				continue
-			}
+	entry := BruEntry{Key: tok.name, Value: tok.value, Enabled: true, Line: tok.line}
+	if strings.HasPrefix(entry.Key, "~") {
+		entry.Key = strings.TrimPrefix(entry.Key, "~")
+		entry.Enabled = false
+	}
+	switch entry.Value {
+	case "'''":
+		entry.Value = p.parseMultiline(tok, entry.Key)
+	case "[":
+		entry.Value = ""
+		entry.Block = p.parseList(tok, entry.Key)
+	}
+	block.Entries = append(block.Entries, entry)
+}
 
-			if strings.HasSuffix(trimmedLine, "request: {") {
-				currentBlock = "example-request"
-				blockIndents[currentBlock] = indent
-				continue
-			}
-			if strings.HasSuffix(trimmedLine, "response: {") {
-				currentBlock = "example-response"
-				blockIndents[currentBlock] = indent
-				continue
+// parseList reads a "key: [" list value, one item per line up to the closing "]"
+func (p *bruParser) parseList(open token, key string) *BruBlock {
+	list := &BruBlock{Name: key, Kind: ArrayBlock, Line: open.line}
+	for {
+		tok := p.lexer.peek()
+		if tok.kind == tokenEOF {
+			if !p.eofReported {
+				p.errorf(open, "list '%s' is not closed", key)
+				p.eofReported = true
 			}
+			return list
+		}
+		p.lexer.next()
+		items, closed := strings.CutSuffix(strings.TrimSpace(tok.raw), "]")
+		list.Items = append(list.Items, parseArrayItems(items, tok.line)...)
+		if closed {
+			return list
+		}
+	}
+}
 
-			parts := strings.SplitN(trimmedLine, ":", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				val := strings.TrimSpace(parts[1])
-				if key == "name" {
-					bru.Examples[idx].Name = val
-				}
+// parseMultiline reads a triple-quoted value, closed by the quotes at the indentation of its key
+func (p *bruParser) parseMultiline(open token, key string) string {
+	var sb strings.Builder
+	for {
+		tok := p.lexer.peek()
+		if tok.kind == tokenEOF {
+			if !p.eofReported {
+				p.errorf(open, "multiline value of '%s' is not closed", key)
+				p.eofReported = true
 			}
+			break
+		}
+		p.lexer.next()
+		if strings.TrimSpace(tok.raw) == "'''" && tok.indent == open.indent {
+			break
+		}
+		sb.WriteString(tok.raw + "\n")
+	}
+	return strings.TrimSuffix(outdent(sb.String(), open.indent+"  "), "\n")
+}
 
-		case "example-request":
-			if idx == -1 {
-				continue
+func (p *bruParser) parseText(block *BruBlock, open token, parent *BruBlock) {
+	var sb strings.Builder
+	closing := open.indent + "}"
+	defer func() {
+		block.Text = outdent(sb.String(), open.indent+"  ")
+	}()
+
+	for {
+		tok := p.lexer.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			if parent == nil {
+				p.unclosed(open)
 			}
-			if strings.HasSuffix(trimmedLine, "body:json: {") || strings.HasSuffix(trimmedLine, "body: {") {
-				currentBlock = "example-request-body"
-				blockIndents[currentBlock] = indent
-				continue
+			return
+		case tok.raw == closing:
+			p.lexer.next()
+			if p.closesText(open) {
+				return
 			}
-			// Parse method, url
-			parts := strings.SplitN(trimmedLine, ":", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				val := strings.TrimSpace(parts[1])
-				if key == "url" {
-					bru.Examples[idx].Request.Url = val
-				} else if key == "method" {
-					bru.Examples[idx].Request.Method = val
+			// More text follows: the brace belongs to the content
+		case parent == nil && startsBlock(tok, open):
+			p.unclosed(open)
+			return
+		default:
+			p.lexer.next()
+		}
+
+		if tok.kind != tokenBlank && !strings.HasPrefix(tok.raw, open.indent+"  ") && !strings.HasPrefix(tok.raw, open.indent+"\t") {
+			p.errorf(tok, "content of block '%s' must be indented", block.Name)
+		}
+		sb.WriteString(tok.raw + "\n")
+	}
+}
+
+// closesText reports whether a "}" at the indentation of a text block ends
+// it: it does when the next line opens another block or closes the parent one
+func (p *bruParser) closesText(open token) bool {
+	next := p.lexer.peekSignificant()
+	switch next.kind {
+	case tokenEOF:
+		return true
+	case tokenOpen:
+		return len(next.indent) <= len(open.indent)
+	case tokenClose:
+		return len(next.indent) < len(open.indent)
+	}
+	return false
+}
+
+func (p *bruParser) parseArray(block *BruBlock, open token) {
+	// The items can follow the "[" on the same line: vars:secret [a, b]
+	if items, ok := strings.CutSuffix(open.value, "]"); ok {
+		block.Items = append(block.Items, parseArrayItems(items, open.line)...)
+		return
+	}
+	block.Items = append(block.Items, parseArrayItems(open.value, open.line)...)
+
+	for {
+		tok := p.lexer.peek()
+		if tok.kind == tokenEOF || startsBlock(tok, open) {
+			p.unclosed(open)
+			return
+		}
+		p.lexer.next()
+		items, closed := strings.CutSuffix(strings.TrimSpace(tok.raw), "]")
+		block.Items = append(block.Items, parseArrayItems(items, tok.line)...)
+		if closed {
+			return
+		}
+	}
+}
+
+// parseArrayItems parses a comma separated list of array items, "~" disables an item
+func parseArrayItems(list string, line int) []BruEntry {
+	var items []BruEntry
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		items = append(items, BruEntry{Key: strings.TrimPrefix(name, "~"), Enabled: !strings.HasPrefix(name, "~"), Line: line})
+	}
+	return items
+}
+
+// blockKind returns whether a block holds text or a dictionary.
+// Blocks bru-ship doesn't read are kept as text.
+func blockKind(name string, parent *BruBlock) string {
+	if parent != nil {
+		// "body:json: {" of example requests holds text, "body: {" of responses holds type and content
+		if strings.HasPrefix(name, "body:") || (name == "body" && parent.Name == "request") {
+			return TextBlock
+		}
+		return DictBlock
+	}
+	if isTextBlock(name) || !isKnownBlock(name) {
+		return TextBlock
+	}
+	return DictBlock
+}
+
+// isKnownBlock reports whether a top-level block is read by bru-ship
+func isKnownBlock(name string) bool {
+	return isDataBlock(name) || isMethodBlock(name) || name == "vars" || name == "vars:secret"
+}
+
+// isBlockName reports whether a word can name a block
+func isBlockName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\"'")
+}
+
+// bruFileFromDocument builds the BruFile of a request, folder.bru or collection.bru
+func bruFileFromDocument(doc *BruDocument) *BruFile {
+	bru := &BruFile{
+		Headers: []KeyValue{},
+		Vars:    []KeyValue{},
+		Auth:    make(map[string]string),
+	}
+	// Bruno keeps the text body of every mode used so far, only the one of
+	// the body mode is sent
+	bodies := make(map[string]string)
+
+	for _, block := range doc.Blocks {
+		switch {
+		case block.Name == "meta":
+			for _, e := range block.Entries {
+				if !e.Enabled {
+					continue
 				}
-			}
-			if trimmedLine == "}" {
-				if line == blockIndents[currentBlock]+"}" {
-					currentBlock = "example"
+				switch e.Key {
+				case "name":
+					bru.Name = e.Value
+				case "type":
+					bru.Type = e.Value
+				case "seq":
+					fmt.Sscanf(e.Value, "%d", &bru.Seq)
+				case "tags":
+					bru.Tags = listValue(e)
 				}
 			}
-
-		case "example-request-body":
-			if idx == -1 {
-				continue
-			}
-			if trimmedLine == "}" {
-				if line == blockIndents[currentBlock]+"}" {
-					currentBlock = "example-request"
-				} else {
-					bru.Examples[idx].Request.Body += line + "\n"
+		case isMethodBlock(block.Name):
+			bru.Method = strings.ToUpper(block.Name)
+			for _, e := range enabledEntries(block) {
+				switch e.Key {
+				case "url":
+					bru.Url = e.Value
+				case "body":
+					bru.BodyMode = e.Value
+				case "auth":
+					bru.Auth["mode"] = e.Value
 				}
-			} else {
-				bru.Examples[idx].Request.Body += line + "\n"
 			}
-
-		case "example-response":
-			if idx == -1 {
-				continue
+		case block.Name == "example":
+			bru.Examples = append(bru.Examples, exampleFromBlock(block))
+		case block.Name == "docs":
+			bru.Docs = block.Text
+		case block.Name == "script:pre-request":
+			bru.PreRequestScript = block.Text
+		case block.Name == "script:post-response":
+			bru.PostResponseScript = block.Text
+		case block.Name == "tests":
+			bru.Tests = block.Text
+		case strings.HasPrefix(block.Name, "body"):
+			if block.Name == "body:graphql:vars" {
+				bru.GraphqlVars = block.Text
+			} else if block.Kind == TextBlock {
+				bodies[block.Name] = block.Text
 			}
-			if strings.HasSuffix(trimmedLine, "body: {") {
-				currentBlock = "example-response-body"
-				blockIndents[currentBlock] = indent
-				continue
+			for _, e := range block.Entries {
+				addDictEntry(bru, block.Name, KeyValue{Key: e.Key, Value: e.Value, Enabled: e.Enabled})
 			}
-			if strings.HasSuffix(trimmedLine, "status: {") {
-				currentBlock = "example-response-status"
-				blockIndents[currentBlock] = indent
-				continue
+			if bru.BodyMode == "" {
+				bru.BodyMode = bodyModeFromBlock(block.Name)
 			}
-			if strings.HasSuffix(trimmedLine, "headers: {") {
-				currentBlock = "example-response-headers"
-				blockIndents[currentBlock] = indent
-				continue
+		case block.Name == "settings":
+			for _, e := range block.Entries {
+				bru.Settings = append(bru.Settings, KeyValue{Key: e.Key, Value: e.Value, Enabled: e.Enabled})
 			}
-			if trimmedLine == "}" {
-				if line == blockIndents[currentBlock]+"}" {
-					currentBlock = "example"
+		case block.Kind == DictBlock:
+			for _, e := range block.Entries {
+				kv := KeyValue{Key: e.Key, Value: e.Value, Enabled: e.Enabled}
+				if strings.HasPrefix(block.Name, "auth") && !e.Enabled {
+					kv.Key = "~" + e.Key
 				}
+				addDictEntry(bru, block.Name, kv)
 			}
+		}
+	}
 
-		case "example-response-status":
-			if idx == -1 {
-				continue
-			}
-			if trimmedLine == "}" {
-				if line == blockIndents[currentBlock]+"}" {
-					currentBlock = "example-response"
-				}
-			} else {
-				parts := strings.SplitN(trimmedLine, ":", 2)
-				if len(parts) == 2 {
-					key := strings.TrimSpace(parts[0])
-					val := strings.TrimSpace(parts[1])
-					if key == "code" {
-						fmt.Sscanf(val, "%d", &bru.Examples[idx].Response.Status)
-					} else if key == "text" {
-						bru.Examples[idx].Response.StatusText = val
+	if body, ok := bodies[bodyBlocks[bru.BodyMode]]; ok {
+		bru.Body = body
+		delete(bodies, bodyBlocks[bru.BodyMode])
+	}
+	if len(bodies) > 0 {
+		bru.OtherBodies = bodies
+	}
+	return bru
+}
+
+// exampleFromBlock builds a saved response example from its example block
+func exampleFromBlock(block *BruBlock) BruExample {
+	var ex BruExample
+	for _, e := range block.Entries {
+		switch {
+		case e.Block == nil && e.Key == "name":
+			ex.Name = e.Value
+		case e.Block != nil && e.Key == "request":
+			for _, r := range e.Block.Entries {
+				switch {
+				case r.Block != nil && r.Block.Kind == TextBlock:
+					if ex.Request.BodyMode == "" {
+						ex.Request.BodyMode = strings.TrimPrefix(r.Key, "body:")
 					}
+					ex.Request.Body = r.Block.Text
+				case r.Block != nil && r.Key == "headers":
+					ex.Request.Headers = exampleKeyValues(r.Block)
+				case r.Block != nil && r.Key == "params:query":
+					ex.Request.Query = exampleKeyValues(r.Block)
+				case r.Key == "url":
+					ex.Request.Url = r.Value
+				case r.Key == "method":
+					ex.Request.Method = r.Value
+				case r.Key == "mode":
+					ex.Request.BodyMode = r.Value
 				}
 			}
-
-		case "example-response-headers":
-			if idx == -1 {
-				continue
-			}
-			if trimmedLine == "}" {
-				if line == blockIndents[currentBlock]+"}" {
-					currentBlock = "example-response"
-				}
-			} else {
-				parts := strings.SplitN(trimmedLine, ":", 2)
-				if len(parts) == 2 {
-					bru.Examples[idx].Response.Headers = append(bru.Examples[idx].Response.Headers, KeyValue{
-						Key:   strings.TrimSpace(parts[0]),
-						Value: strings.TrimSpace(parts[1]),
-					})
+		case e.Block != nil && e.Key == "response":
+			for _, r := range e.Block.Entries {
+				if r.Block == nil {
+					continue
 				}
-			}
-
-		case "example-response-body":
-			if idx == -1 {
-				continue
-			}
-			if trimmedLine == "}" {
-				if line == blockIndents[currentBlock]+"}" {
-					currentBlock = "example-response"
-				} else {
-					// Handle content: ''' ... '''
-					if strings.Contains(line, "'''") {
-						// Just skip the triple quotes lines for now or handle them?
-						// The content is usually inside triple quotes.
-						// Let's just append everything for now and clean up later if needed.
-						if !strings.Contains(line, "content:") && strings.TrimSpace(line) != "'''" {
-							bru.Examples[idx].Response.Body += line + "\n"
+				switch r.Key {
+				case "headers":
+					ex.Response.Headers = exampleKeyValues(r.Block)
+				case "status":
+					for _, s := range r.Block.Entries {
+						if s.Key == "code" {
+							fmt.Sscanf(s.Value, "%d", &ex.Response.Status)
+						} else if s.Key == "text" {
+							ex.Response.StatusText = s.Value
 						}
-					} else {
-						if strings.TrimSpace(line) != "type: json" {
-							bru.Examples[idx].Response.Body += line + "\n"
-						}
-					}
-				}
-			} else {
-				// Handle content: ''' ... '''
-				if strings.Contains(line, "'''") {
-					if !strings.Contains(line, "content:") && strings.TrimSpace(line) != "'''" {
-						bru.Examples[idx].Response.Body += line + "\n"
 					}
-				} else {
-					if strings.TrimSpace(line) != "type: json" {
-						bru.Examples[idx].Response.Body += line + "\n"
+				case "body":
+					for _, b := range r.Block.Entries {
+						if b.Key == "content" && b.Value != "" {
+							ex.Response.Body = b.Value + "\n"
+						}
 					}
 				}
 			}
+		}
+	}
+	return ex
+}
 
-		default:
-			if strings.HasPrefix(currentBlock, "auth") {
-				parts := strings.SplitN(trimmedLine, ":", 2)
-				if len(parts) == 2 {
-					key := strings.TrimSpace(parts[0])
-					val := strings.TrimSpace(parts[1])
-					bru.Auth[key] = val
-				}
-			} else if strings.HasPrefix(currentBlock, "body") {
-				// fmt.Printf("DEBUG: Writing to bodyBuffer: %s\n", line)
-				bodyBuffer.WriteString(line + "\n")
-			}
+// exampleKeyValues returns the "key: value" entries of a block nested in an example
+func exampleKeyValues(block *BruBlock) []KeyValue {
+	var list []KeyValue
+	for _, e := range block.Entries {
+		if e.Block == nil {
+			list = append(list, KeyValue{Key: e.Key, Value: e.Value, Enabled: e.Enabled})
 		}
 	}
+	return list
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+// listValue returns the items of a list entry, written over several lines
+// ("tags: [" then one item per line) or on one line ("tags: [a, b]")
+func listValue(e BruEntry) []string {
+	var items []BruEntry
+	if e.Block != nil {
+		items = e.Block.Items
+	} else if inner, ok := strings.CutPrefix(e.Value, "["); ok {
+		items = parseArrayItems(strings.TrimSuffix(inner, "]"), e.Line)
+	} else {
+		items = parseArrayItems(e.Value, e.Line)
 	}
+	var values []string
+	for _, item := range items {
+		if item.Enabled {
+			values = append(values, item.Key)
+		}
+	}
+	return values
+}
 
-	return bru, nil
+// enabledEntries returns the "key: value" entries of a block that aren't disabled with "~"
+func enabledEntries(block *BruBlock) []BruEntry {
+	var entries []BruEntry
+	for _, e := range block.Entries {
+		if e.Enabled && e.Block == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries
 }
 
-// ParseEnvFile parses a Bruno environment file and returns a map of variables
-func ParseEnvFile(path string) (map[string]string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
+// snippetOf shortens a line of the file to be shown in an error
+func snippetOf(line string) string {
+	line = strings.TrimRight(line, " \t\r")
+	if len(line) > 80 {
+		return line[:77] + "..."
 	}
-	defer file.Close()
+	return line
+}
 
-	vars := make(map[string]string)
-	scanner := bufio.NewScanner(file)
-	var currentBlock string
+// addDictEntry stores an entry of a dictionary block in the BruFile field it belongs to
+func addDictEntry(bru *BruFile, block string, kv KeyValue) {
+	switch block {
+	case "headers":
+		bru.Headers = append(bru.Headers, kv)
+	case "params:query":
+		bru.Query = append(bru.Query, kv)
+	case "params:path":
+		bru.Params = append(bru.Params, kv)
+	case "vars:pre-request":
+		bru.Vars = append(bru.Vars, kv)
+	case "vars:post-response":
+		bru.Vars = append(bru.Vars, kv)
+		bru.PostResponseVars = append(bru.PostResponseVars, kv)
+	case "assert":
+		bru.Asserts = append(bru.Asserts, kv)
+	case "body:form-urlencoded":
+		bru.FormUrlEncoded = append(bru.FormUrlEncoded, kv)
+	case "body:multipart-form":
+		bru.MultipartForm = append(bru.MultipartForm, kv)
+	default:
+		if strings.HasPrefix(block, "auth") {
+			bru.Auth[kv.Key] = kv.Value
+		}
+	}
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmedLine := strings.TrimSpace(line)
+// outdent removes the block indentation from every line of a text block
+func outdent(text string, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, indent)
+	}
+	return strings.Join(lines, "\n")
+}
 
-		if trimmedLine == "" {
-			continue
-		}
+// isDataBlock reports whether a block is read into a BruFile field (method blocks aside)
+func isDataBlock(block string) bool {
+	return block == "meta" || block == "headers" || block == "vars:pre-request" || block == "vars:post-response" || block == "params:query" || block == "params:path" || strings.HasPrefix(block, "body") || block == "docs" || isScriptBlock(block) || block == "assert" || strings.HasPrefix(block, "auth") || block == "example" || block == "settings"
+}
 
-		if strings.HasSuffix(trimmedLine, " {") {
-			blockName := strings.TrimSuffix(trimmedLine, " {")
-			if blockName == "vars" {
-				currentBlock = "vars"
-				continue
-			}
-		}
+// isMethodBlock reports whether a block is the request block of an HTTP method
+func isMethodBlock(block string) bool {
+	switch block {
+	case "get", "post", "put", "delete", "patch", "options", "head":
+		return true
+	}
+	return false
+}
 
-		if trimmedLine == "}" {
-			currentBlock = ""
-			continue
-		}
+// isTextBlock reports whether a block holds free text instead of key/value pairs
+func isTextBlock(block string) bool {
+	return (strings.HasPrefix(block, "body") && !isFormBodyBlock(block)) || block == "docs" || isScriptBlock(block)
+}
 
-		if currentBlock == "vars" {
-			parts := strings.SplitN(trimmedLine, ":", 2)
-			if len(parts) == 2 {
-				vars[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-			}
-		}
+// isScriptBlock reports whether a block holds JavaScript code
+func isScriptBlock(block string) bool {
+	return block == "script:pre-request" || block == "script:post-response" || block == "tests"
+}
+
+// isFormBodyBlock reports whether a body block holds key/value pairs instead of text
+func isFormBodyBlock(block string) bool {
+	return block == "body:form-urlencoded" || block == "body:multipart-form"
+}
+
+// bodyModeFromBlock maps a body block name to the body mode used in the request block
+func bodyModeFromBlock(block string) string {
+	switch block {
+	case "body:form-urlencoded":
+		return "formUrlEncoded"
+	case "body:multipart-form":
+		return "multipartForm"
+	case "body:graphql:vars":
+		return "graphql"
+	default:
+		return strings.TrimPrefix(block, "body:")
 	}
+}
 
-	if err := scanner.Err(); err != nil {
+// ParseEnvFile parses a Bruno environment file and returns a map of the enabled variables
+func ParseEnvFile(path string) (map[string]string, error) {
+	env, err := ParseEnvironment(path)
+	if err != nil {
 		return nil, err
 	}
 
+	vars := make(map[string]string)
+	for _, v := range env.Vars {
+		if v.Enabled {
+			vars[v.Key] = v.Value
+		}
+	}
 	return vars, nil
 }
+
+// ParseEnvironment parses a Bruno environment file, keeping the variables
+// in order and the names listed in the vars:secret block
+func ParseEnvironment(path string) (*BruEnvironment, error) {
+	doc, err := parseBruPath(path)
+	if doc == nil {
+		return nil, err
+	}
+	return environmentFromDocument(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), doc), err
+}
+
+// environmentFromDocument builds a BruEnvironment from its vars and vars:secret blocks.
+// Disabled (~) secret names are still secrets.
+func environmentFromDocument(name string, doc *BruDocument) *BruEnvironment {
+	env := &BruEnvironment{Name: name, Vars: []KeyValue{}}
+	for _, block := range doc.Blocks {
+		switch block.Name {
+		case "vars":
+			for _, e := range block.Entries {
+				env.Vars = append(env.Vars, KeyValue{Key: e.Key, Value: e.Value, Enabled: e.Enabled})
+			}
+		case "vars:secret":
+			for _, item := range block.Items {
+				env.Secrets = append(env.Secrets, KeyValue{Key: item.Key, Enabled: item.Enabled})
+			}
+		}
+	}
+	return env
+}
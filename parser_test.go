@@ -1,7 +1,12 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -71,3 +76,460 @@ vars:post-response {
 		t.Error("Expected body to be parsed")
 	}
 }
+
+func TestParseBruFile_DisabledHeaders(t *testing.T) {
+	content := `meta {
+  name: Login
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/auth/login
+  body: none
+  auth: none
+}
+
+headers {
+  Accept: application/json
+  ~Authorization: Bearer old-token
+}
+`
+	tmpFile := filepath.Join(t.TempDir(), "login.bru")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bru, err := ParseBruFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(bru.Headers) != 2 {
+		t.Fatalf("Expected 2 headers, got %d", len(bru.Headers))
+	}
+	if bru.Headers[0].Key != "Accept" || !bru.Headers[0].Enabled {
+		t.Errorf("Expected enabled header Accept, got %+v", bru.Headers[0])
+	}
+	if bru.Headers[1].Key != "Authorization" || bru.Headers[1].Value != "Bearer old-token" || bru.Headers[1].Enabled {
+		t.Errorf("Expected disabled header Authorization, got %+v", bru.Headers[1])
+	}
+}
+
+func TestParseBruFile_Params(t *testing.T) {
+	content := `meta {
+  name: Get User
+  type: http
+  seq: 2
+}
+
+get {
+  url: {{baseUrl}}/users/:id?expand=profile
+  body: none
+  auth: none
+}
+
+params:query {
+  expand: profile
+  ~debug: true
+}
+
+params:path {
+  id: 42
+}
+`
+	tmpFile := filepath.Join(t.TempDir(), "get-user.bru")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bru, err := ParseBruFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(bru.Query) != 2 {
+		t.Fatalf("Expected 2 query params, got %d", len(bru.Query))
+	}
+	if bru.Query[0].Key != "expand" || bru.Query[0].Value != "profile" || !bru.Query[0].Enabled {
+		t.Errorf("Unexpected first query param: %+v", bru.Query[0])
+	}
+	if bru.Query[1].Key != "debug" || bru.Query[1].Enabled {
+		t.Errorf("Expected disabled query param debug, got %+v", bru.Query[1])
+	}
+	if len(bru.Params) != 1 || bru.Params[0].Key != "id" || bru.Params[0].Value != "42" {
+		t.Errorf("Unexpected path params: %+v", bru.Params)
+	}
+}
+
+func TestParseBruFile_FormBodies(t *testing.T) {
+	content := `meta {
+  name: Upload
+  type: http
+}
+
+post {
+  url: {{baseUrl}}/upload
+  body: multipartForm
+  auth: none
+}
+
+body:form-urlencoded {
+  grant_type: password
+}
+
+body:multipart-form {
+  title: Avatar
+  ~draft: true
+  file: @file(images/avatar.png)
+}
+`
+	tmpFile := filepath.Join(t.TempDir(), "upload.bru")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bru, err := ParseBruFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if bru.BodyMode != "multipartForm" {
+		t.Errorf("Expected body mode multipartForm, got %s", bru.BodyMode)
+	}
+	if bru.Body != "" {
+		t.Errorf("Expected form blocks to stay out of the text body, got %q", bru.Body)
+	}
+	if len(bru.FormUrlEncoded) != 1 || bru.FormUrlEncoded[0].Key != "grant_type" {
+		t.Errorf("Unexpected urlencoded fields: %+v", bru.FormUrlEncoded)
+	}
+	if len(bru.MultipartForm) != 3 {
+		t.Fatalf("Expected 3 multipart fields, got %d", len(bru.MultipartForm))
+	}
+	if bru.MultipartForm[1].Key != "draft" || bru.MultipartForm[1].Enabled {
+		t.Errorf("Expected disabled multipart field draft, got %+v", bru.MultipartForm[1])
+	}
+	if bru.MultipartForm[2].Value != "@file(images/avatar.png)" {
+		t.Errorf("Unexpected file field value: %s", bru.MultipartForm[2].Value)
+	}
+}
+
+func TestParseBruFile_GraphQL(t *testing.T) {
+	content := `meta {
+  name: Get Users
+  type: graphql
+}
+
+post {
+  url: {{baseUrl}}/graphql
+  body: graphql
+  auth: none
+}
+
+body:graphql {
+  query Users($limit: Int) {
+    users(limit: $limit) {
+      id
+    }
+  }
+}
+
+body:graphql:vars {
+  {
+    "limit": 10
+  }
+}
+`
+	tmpFile := filepath.Join(t.TempDir(), "users.bru")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bru, err := ParseBruFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if bru.Type != "graphql" || bru.BodyMode != "graphql" {
+		t.Errorf("Expected graphql type and body mode, got %s/%s", bru.Type, bru.BodyMode)
+	}
+	if !strings.Contains(bru.Body, "users(limit: $limit)") {
+		t.Errorf("Expected query in body, got %q", bru.Body)
+	}
+	if strings.Contains(bru.Body, `"limit": 10`) {
+		t.Errorf("Expected variables to stay out of the query, got %q", bru.Body)
+	}
+	if !strings.Contains(bru.GraphqlVars, `"limit": 10`) {
+		t.Errorf("Expected variables in GraphqlVars, got %q", bru.GraphqlVars)
+	}
+}
+
+func TestParseBruFile_Scripts(t *testing.T) {
+	content := `meta {
+  name: Login
+  type: http
+}
+
+post {
+  url: {{baseUrl}}/login
+  body: none
+  auth: none
+}
+
+script:pre-request {
+  const ts = Date.now();
+
+  bru.setVar("ts", ts);
+}
+
+script:post-response {
+  if (res.status === 200) {
+    bru.setVar("token", res.body.token);
+  }
+}
+
+assert {
+  res.status: eq 200
+  ~res.body.token: isString
+}
+
+tests {
+  test("has token", function() {
+    expect(res.body.token).to.be.a("string");
+  });
+}
+`
+	tmpFile := filepath.Join(t.TempDir(), "login.bru")
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bru, err := ParseBruFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if bru.PreRequestScript != "const ts = Date.now();\n\nbru.setVar(\"ts\", ts);\n" {
+		t.Errorf("Unexpected pre-request script: %q", bru.PreRequestScript)
+	}
+	if !strings.HasSuffix(bru.PostResponseScript, "\n}\n") || !strings.Contains(bru.PostResponseScript, "res.body.token") {
+		t.Errorf("Unexpected post-response script: %q", bru.PostResponseScript)
+	}
+	if len(bru.Asserts) != 2 || bru.Asserts[0].Key != "res.status" || bru.Asserts[0].Value != "eq 200" || bru.Asserts[1].Enabled {
+		t.Errorf("Unexpected asserts: %+v", bru.Asserts)
+	}
+	if !strings.Contains(bru.Tests, `expect(res.body.token).to.be.a("string");`) {
+		t.Errorf("Unexpected tests: %q", bru.Tests)
+	}
+}
+
+func TestParseBruFile_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string // line:column: message
+	}{
+		{
+			name:     "unclosed block",
+			content:  "meta {\n  name: Ping\n}\n\nget {\n  url: https://example.com\n\nheaders {\n  Accept: */*\n}\n",
+			expected: []string{"5:1: block 'get {' is not closed"},
+		},
+		{
+			name:     "unclosed block at end of file",
+			content:  "meta {\n  name: Ping\n}\n\nbody:json {\n  {}\n",
+			expected: []string{"5:1: block 'body:json {' is not closed"},
+		},
+		{
+			name:     "stray brace",
+			content:  "meta {\n  name: Ping\n}\n}\n",
+			expected: []string{"4:1: unexpected '}' outside of a block"},
+		},
+		{
+			name:     "misindented body",
+			content:  "body:json {\n  {\n\"id\": 1\n  }\n}\n",
+			expected: []string{"3:1: content of block 'body:json' must be indented"},
+		},
+		{
+			name:     "misaligned closing brace",
+			content:  "headers {\n  Accept: */*\n  }\n",
+			expected: []string{"3:3: closing brace of block 'headers {' is not aligned with its opening line 1"},
+		},
+		{
+			name:     "missing colon and unclosed multiline value",
+			content:  "headers {\n  Accept\n  X-Note: '''\n    text\n}\n",
+			expected: []string{"2:3: expected 'key: value' in block 'headers'", "3:3: multiline value of 'X-Note' is not closed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Ping.bru")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			bru, err := ParseBruFile(path)
+			if bru == nil {
+				t.Fatalf("Expected the partially parsed file, got nil")
+			}
+			errs, ok := err.(ParseErrors)
+			if !ok {
+				t.Fatalf("Expected ParseErrors, got %v", err)
+			}
+			var got []string
+			for _, e := range errs {
+				if e.Path != path {
+					t.Errorf("Expected path %s, got %s", path, e.Path)
+				}
+				got = append(got, fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Unexpected errors:\n%s", strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	err := &ParseError{Path: "Ping.bru", Line: 3, Column: 1, Snippet: "}", Message: "unexpected '}' outside of a block"}
+	if err.Error() != "Ping.bru:3:1: unexpected '}' outside of a block\n    }" {
+		t.Errorf("Unexpected message: %s", err.Error())
+	}
+}
+
+func TestParseBruFile_LongLines(t *testing.T) {
+	// A 4 MB minified JSON body and a 3 MB base64 payload, both on a single line
+	minified := `{"data":"` + strings.Repeat("x", 4<<20) + `"}`
+	payload := strings.Repeat("QUJD", 3<<18)
+	// A response example spread over many lines
+	var rows []string
+	for i := 0; i < 50000; i++ {
+		rows = append(rows, fmt.Sprintf(`{"id": %d},`, i))
+	}
+	multiline := "[\n" + strings.Join(rows, "\n") + "\n]\n"
+
+	bru := &BruFile{
+		Name:     "Upload",
+		Type:     "http",
+		Method:   "POST",
+		Url:      "{{baseUrl}}/upload",
+		BodyMode: "json",
+		Body:     minified + "\n",
+		Headers:  []KeyValue{{Key: "X-Payload", Value: payload, Enabled: true}},
+		Auth:     map[string]string{},
+		Vars:     []KeyValue{},
+		Examples: []BruExample{
+			{Name: "Payload", Request: BruRequest{Url: "{{baseUrl}}/upload", Method: "post"}, Response: BruResponse{Status: 200, Body: payload + "\n"}},
+			{Name: "List", Request: BruRequest{Url: "{{baseUrl}}/upload", Method: "post"}, Response: BruResponse{Status: 200, Body: multiline}},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "Upload.bru")
+	if err := WriteBruFile(path, bru); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseBruFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if parsed.Body != bru.Body {
+		t.Errorf("Expected the %d bytes body, got %d bytes", len(bru.Body), len(parsed.Body))
+	}
+	if len(parsed.Headers) != 1 || parsed.Headers[0].Value != payload {
+		t.Errorf("Expected the long header value to be kept")
+	}
+	if len(parsed.Examples) != 2 || parsed.Examples[0].Response.Body != payload+"\n" || parsed.Examples[1].Response.Body != multiline {
+		t.Errorf("Expected the example bodies to be kept")
+	}
+}
+
+func TestLineReader(t *testing.T) {
+	long := strings.Repeat("a", 100000)
+	reader := newLineReader(strings.NewReader("first\r\n" + long + "\n\nlast"))
+	var lines []string
+	for reader.Scan() {
+		lines = append(lines, reader.Text())
+	}
+	if reader.Err() != nil {
+		t.Fatalf("Expected no error, got %v", reader.Err())
+	}
+	if len(lines) != 4 || lines[0] != "first" || lines[1] != long || lines[2] != "" || lines[3] != "last" {
+		t.Errorf("Unexpected lines: %d", len(lines))
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of TestParseBru_Golden")
+
+// TestParseBru_Golden parses the files of testdata/golden, as written by
+// Bruno, and compares the syntax tree and the BruFile (or BruEnvironment)
+// built from it with the .golden.json files. Run with -update to rewrite them.
+func TestParseBru_Golden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "golden", "*.bru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	environments, _ := filepath.Glob(filepath.Join("testdata", "golden", "environments", "*.bru"))
+	fixtures = append(fixtures, environments...)
+	if len(fixtures) == 0 {
+		t.Fatal("no golden fixtures found")
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			file, err := os.Open(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			doc, err := ParseBru(file, fixture)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			result := map[string]interface{}{"ast": doc}
+			if isEnvironmentFile(fixture) {
+				result["environment"] = environmentFromDocument("Local", doc)
+			} else {
+				result["file"] = bruFileFromDocument(doc)
+			}
+			got, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(fixture, ".bru") + ".golden.json"
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Missing golden file, run the test with -update: %v", err)
+			}
+			if string(got) != string(expected) {
+				t.Errorf("Parse result differs from %s:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestParseBru_BraceInText(t *testing.T) {
+	// A "}" at the indentation of the block only closes it when a block follows
+	content := "body:json {\n  {\n    \"a\": 1\n}\n  ,\n  {}\n}\n\ndocs {\n  text\n}\n"
+	doc, err := ParseBru(strings.NewReader(content), "Ping.bru")
+
+	errs, ok := err.(ParseErrors)
+	if !ok || len(errs) != 1 || errs[0].Line != 4 {
+		t.Fatalf("Expected an indentation error on line 4, got %v", err)
+	}
+	if len(doc.Blocks) != 2 {
+		t.Fatalf("Expected 2 blocks, got %d", len(doc.Blocks))
+	}
+	if doc.Blocks[0].Text != "{\n  \"a\": 1\n}\n,\n{}\n" {
+		t.Errorf("Unexpected body: %q", doc.Blocks[0].Text)
+	}
+	if doc.Blocks[1].Name != "docs" || doc.Blocks[1].Text != "text\n" {
+		t.Errorf("Unexpected docs block: %+v", doc.Blocks[1])
+	}
+}
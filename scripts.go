@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// scriptReplacement rewrites a Bruno scripting API call to its Postman equivalent
+type scriptReplacement struct {
+	pattern *regexp.Regexp
+	replace string
+}
+
+// scriptReplacements is ordered: more specific patterns must come first
+var scriptReplacements = []scriptReplacement{
+	// Variables
+	{regexp.MustCompile(`\bbru\.setVar\(`), "pm.variables.set("},
+	{regexp.MustCompile(`\bbru\.getVar\(`), "pm.variables.get("},
+	{regexp.MustCompile(`\bbru\.setEnvVar\(`), "pm.environment.set("},
+	{regexp.MustCompile(`\bbru\.getEnvVar\(`), "pm.environment.get("},
+	{regexp.MustCompile(`\bbru\.getCollectionVar\(`), "pm.collectionVariables.get("},
+	{regexp.MustCompile(`\bbru\.getRequestVar\(`), "pm.variables.get("},
+	{regexp.MustCompile(`\bbru\.setNextRequest\(`), "pm.execution.setNextRequest("},
+
+	// Response
+	{regexp.MustCompile(`\bres\.getBody\(\)`), "pm.response.json()"},
+	{regexp.MustCompile(`\bres\.getStatus\(\)`), "pm.response.code"},
+	{regexp.MustCompile(`\bres\.getHeaders\(\)`), "pm.response.headers.toObject()"},
+	{regexp.MustCompile(`\bres\.getHeader\(`), "pm.response.headers.get("},
+	{regexp.MustCompile(`\bres\.getResponseTime\(\)`), "pm.response.responseTime"},
+	{regexp.MustCompile(`\bres\.body\b`), "pm.response.json()"},
+	{regexp.MustCompile(`\bres\.status\b`), "pm.response.code"},
+	{regexp.MustCompile(`\bres\.headers\b`), "pm.response.headers.toObject()"},
+	{regexp.MustCompile(`\bres\.responseTime\b`), "pm.response.responseTime"},
+
+	// Request
+	{regexp.MustCompile(`\breq\.setHeader\(\s*([^,()]+?)\s*,\s*((?:[^()]|\([^()]*\))+?)\s*\)`), "pm.request.headers.upsert({ key: $1, value: $2 })"},
+	{regexp.MustCompile(`\breq\.getHeader\(`), "pm.request.headers.get("},
+	{regexp.MustCompile(`\breq\.getUrl\(\)`), "pm.request.url.toString()"},
+	{regexp.MustCompile(`\breq\.getMethod\(\)`), "pm.request.method"},
+}
+
+// untranslatedCall matches any Bruno API usage left after the replacements
+var untranslatedCall = regexp.MustCompile(`\b(bru|req|res)\.[A-Za-z_]\w*(\([^)]*\))?`)
+
+// TranslateScript rewrites the common Bruno scripting APIs (bru.*, req.*, res.*)
+// to Postman's pm.* API. It returns the translated script and the calls that
+// could not be translated.
+func TranslateScript(script string) (string, []string) {
+	for _, r := range scriptReplacements {
+		script = r.pattern.ReplaceAllString(script, r.replace)
+	}
+
+	var untranslated []string
+	seen := make(map[string]bool)
+	for _, call := range untranslatedCall.FindAllString(script, -1) {
+		if !seen[call] {
+			seen[call] = true
+			untranslated = append(untranslated, call)
+		}
+	}
+	return script, untranslated
+}
+
+// scriptLines splits a script into Postman exec lines
+func scriptLines(script string) []string {
+	return strings.Split(strings.TrimRight(script, "\n"), "\n")
+}
+
+var (
+	testCall   = regexp.MustCompile(`(^|[^\w.$])test\(`)
+	expectCall = regexp.MustCompile(`(^|[^\w.$])expect\(`)
+)
+
+// TranslateTests rewrites a Bruno tests block (chai based test()/expect())
+// to pm.test()/pm.expect(), then applies the TranslateScript rewrites
+func TranslateTests(script string) (string, []string) {
+	script = testCall.ReplaceAllString(script, "${1}pm.test(")
+	script = expectCall.ReplaceAllString(script, "${1}pm.expect(")
+	return TranslateScript(script)
+}
+
+// assertOperators maps Bruno assert operators to chai assertions.
+// "%s" is replaced by the operand.
+var assertOperators = map[string]string{
+	"eq":          "to.eql(%s)",
+	"neq":         "to.not.eql(%s)",
+	"gt":          "to.be.above(%s)",
+	"gte":         "to.be.at.least(%s)",
+	"lt":          "to.be.below(%s)",
+	"lte":         "to.be.at.most(%s)",
+	"in":          "to.be.oneOf([%s])",
+	"notIn":       "to.not.be.oneOf([%s])",
+	"contains":    "to.include(%s)",
+	"notContains": "to.not.include(%s)",
+	"length":      "to.have.lengthOf(%s)",
+	"matches":     "to.match(new RegExp(%s))",
+	"notMatches":  "to.not.match(new RegExp(%s))",
+	"startsWith":  "to.satisfy(v => String(v).startsWith(%s))",
+	"endsWith":    "to.satisfy(v => String(v).endsWith(%s))",
+	"between":     "to.be.within(%s)",
+	"isEmpty":     "to.be.empty",
+	"isNotEmpty":  "to.not.be.empty",
+	"isNull":      "to.be.null",
+	"isUndefined": "to.be.undefined",
+	"isDefined":   "to.not.be.undefined",
+	"isTruthy":    "to.be.ok",
+	"isFalsy":     "to.not.be.ok",
+	"isJson":      "to.be.an(\"object\")",
+	"isNumber":    "to.be.a(\"number\")",
+	"isString":    "to.be.a(\"string\")",
+	"isBoolean":   "to.be.a(\"boolean\")",
+	"isArray":     "to.be.an(\"array\")",
+}
+
+// CompileAsserts compiles the entries of a Bruno assert block
+// (e.g. "res.status: eq 200") into pm.test() JavaScript. Disabled entries
+// are skipped. It returns the script lines and the asserts that could
+// not be compiled.
+func CompileAsserts(asserts []KeyValue) ([]string, []string) {
+	var lines []string
+	var unsupported []string
+	for _, a := range asserts {
+		if !a.Enabled {
+			continue
+		}
+		operator, operand, _ := strings.Cut(strings.TrimSpace(a.Value), " ")
+		chai, ok := assertOperators[operator]
+		if !ok {
+			unsupported = append(unsupported, a.Key+": "+a.Value)
+			continue
+		}
+		if strings.Contains(chai, "%s") {
+			chai = strings.Replace(chai, "%s", assertOperand(operator, strings.TrimSpace(operand)), 1)
+		}
+		target, _ := TranslateScript(a.Key)
+		lines = append(lines,
+			fmt.Sprintf("pm.test(%s, function () {", strconv.Quote(a.Key+": "+a.Value)),
+			fmt.Sprintf("    pm.expect(%s).%s;", target, chai),
+			"});",
+		)
+	}
+	return lines, unsupported
+}
+
+// assertOperand converts an assert operand to a JavaScript expression.
+// Lists (in, notIn, between) are comma separated.
+func assertOperand(operator string, operand string) string {
+	if operator == "in" || operator == "notIn" || operator == "between" {
+		values := strings.Split(operand, ",")
+		for i, v := range values {
+			values[i] = assertValue(strings.TrimSpace(v))
+		}
+		return strings.Join(values, ", ")
+	}
+	return assertValue(operand)
+}
+
+// assertValue keeps numbers, booleans, null and quoted strings as they are,
+// turns {{var}} into a variable lookup and quotes anything else
+func assertValue(v string) string {
+	if v == "true" || v == "false" || v == "null" || v == "undefined" {
+		return v
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v
+	}
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'' || v[0] == '`') && v[len(v)-1] == v[0] {
+		return v
+	}
+	if strings.HasPrefix(v, "{{") && strings.HasSuffix(v, "}}") {
+		return fmt.Sprintf("pm.variables.get(%s)", strconv.Quote(strings.TrimSuffix(strings.TrimPrefix(v, "{{"), "}}")))
+	}
+	return strconv.Quote(v)
+}
+
+// postmanReplacements rewrites Postman's pm.* API back to Bruno's scripting API
+var postmanReplacements = []scriptReplacement{
+	{regexp.MustCompile(`\bpm\.request\.headers\.upsert\(\{\s*key:\s*([^,{}]+?)\s*,\s*value:\s*((?:[^{}()]|\([^()]*\))+?)\s*\}\)`), "req.setHeader($1, $2)"},
+	{regexp.MustCompile(`\bpm\.variables\.set\(`), "bru.setVar("},
+	{regexp.MustCompile(`\bpm\.variables\.get\(\s*["'\x60]` + processEnvPrefix + `(\w+)["'\x60]\s*\)`), `bru.getProcessEnv("$1")`},
+	{regexp.MustCompile(`\bpm\.variables\.get\(`), "bru.getVar("},
+	{regexp.MustCompile(`\bpm\.environment\.set\(`), "bru.setEnvVar("},
+	{regexp.MustCompile(`\bpm\.environment\.get\(`), "bru.getEnvVar("},
+	{regexp.MustCompile(`\bpm\.collectionVariables\.get\(`), "bru.getCollectionVar("},
+	{regexp.MustCompile(`\bpm\.execution\.setNextRequest\(`), "bru.setNextRequest("},
+	{regexp.MustCompile(`\bpm\.response\.json\(\)`), "res.getBody()"},
+	{regexp.MustCompile(`\bpm\.response\.code\b`), "res.getStatus()"},
+	{regexp.MustCompile(`\bpm\.response\.headers\.toObject\(\)`), "res.getHeaders()"},
+	{regexp.MustCompile(`\bpm\.response\.headers\.get\(`), "res.getHeader("},
+	{regexp.MustCompile(`\bpm\.response\.responseTime\b`), "res.getResponseTime()"},
+	{regexp.MustCompile(`\bpm\.request\.headers\.get\(`), "req.getHeader("},
+	{regexp.MustCompile(`\bpm\.request\.url\.toString\(\)`), "req.getUrl()"},
+	{regexp.MustCompile(`\bpm\.request\.method\b`), "req.getMethod()"},
+	{regexp.MustCompile(`\bpm\.test\(`), "test("},
+	{regexp.MustCompile(`\bpm\.expect\(`), "expect("},
+}
+
+// untranslatedPostmanCall matches any pm.* usage left after the replacements
+var untranslatedPostmanCall = regexp.MustCompile(`\b(pm|postman)\.[A-Za-z_][\w.]*(\([^)]*\))?`)
+
+// TranslatePostmanScript rewrites the common pm.* APIs of a Postman script to
+// Bruno's scripting API. It returns the translated script and the calls that
+// could not be translated.
+func TranslatePostmanScript(script string) (string, []string) {
+	for _, r := range postmanReplacements {
+		script = r.pattern.ReplaceAllString(script, r.replace)
+	}
+
+	var untranslated []string
+	seen := make(map[string]bool)
+	for _, call := range untranslatedPostmanCall.FindAllString(script, -1) {
+		if !seen[call] {
+			seen[call] = true
+			untranslated = append(untranslated, call)
+		}
+	}
+	return script, untranslated
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranslateScript(t *testing.T) {
+	script := `const data = res.getBody();
+bru.setVar("token", data.token);
+const env = bru.getEnvVar("env");
+req.setHeader("Authorization", "Bearer " + bru.getVar("token"));
+if (res.status === 200) {
+  bru.sleep(100);
+}
+`
+	translated, untranslated := TranslateScript(script)
+
+	expected := []string{
+		`const data = pm.response.json();`,
+		`pm.variables.set("token", data.token);`,
+		`const env = pm.environment.get("env");`,
+		`pm.request.headers.upsert({ key: "Authorization", value: "Bearer " + pm.variables.get("token") });`,
+		`if (pm.response.code === 200) {`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(translated, e) {
+			t.Errorf("Expected translated script to contain %q, got:\n%s", e, translated)
+		}
+	}
+
+	if len(untranslated) != 1 || untranslated[0] != "bru.sleep(100)" {
+		t.Errorf("Expected bru.sleep(100) to be reported as untranslated, got %v", untranslated)
+	}
+}
+
+func TestScriptLines(t *testing.T) {
+	lines := scriptLines("const a = 1;\nif (a) {\n  a++;\n}\n")
+	expected := []string{"const a = 1;", "if (a) {", "  a++;", "}"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %v", len(expected), len(lines), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestTranslateTests(t *testing.T) {
+	script := `test("should be ok", function() {
+  expect(res.getStatus()).to.equal(200);
+  pm.expect(true).to.be.true;
+});
+`
+	translated, untranslated := TranslateTests(script)
+	if !strings.Contains(translated, `pm.test("should be ok", function() {`) {
+		t.Errorf("Expected test() to become pm.test(), got:\n%s", translated)
+	}
+	if !strings.Contains(translated, `pm.expect(pm.response.code).to.equal(200);`) {
+		t.Errorf("Expected expect() to become pm.expect(), got:\n%s", translated)
+	}
+	if strings.Contains(translated, "pm.pm.") {
+		t.Errorf("Expected existing pm.expect() to be kept, got:\n%s", translated)
+	}
+	if len(untranslated) != 0 {
+		t.Errorf("Expected no untranslated calls, got %v", untranslated)
+	}
+}
+
+func TestCompileAsserts(t *testing.T) {
+	asserts := []KeyValue{
+		{Key: "res.status", Value: "eq 200", Enabled: true},
+		{Key: "res.body.id", Value: "isNumber", Enabled: true},
+		{Key: "res.body.role", Value: "in admin, user", Enabled: true},
+		{Key: "res.body.name", Value: "eq {{userName}}", Enabled: true},
+		{Key: "res.body.skipped", Value: "isDefined", Enabled: false},
+		{Key: "res.body.items", Value: "isFancy", Enabled: true},
+	}
+
+	lines, unsupported := CompileAsserts(asserts)
+	script := strings.Join(lines, "\n")
+
+	expected := []string{
+		`pm.test("res.status: eq 200", function () {`,
+		`pm.expect(pm.response.code).to.eql(200);`,
+		`pm.expect(pm.response.json().id).to.be.a("number");`,
+		`pm.expect(pm.response.json().role).to.be.oneOf(["admin", "user"]);`,
+		`pm.expect(pm.response.json().name).to.eql(pm.variables.get("userName"));`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(script, e) {
+			t.Errorf("Expected compiled asserts to contain %q, got:\n%s", e, script)
+		}
+	}
+	if strings.Contains(script, "skipped") {
+		t.Errorf("Expected disabled assert to be skipped, got:\n%s", script)
+	}
+	if len(unsupported) != 1 || unsupported[0] != "res.body.items: isFancy" {
+		t.Errorf("Expected unsupported operator to be reported, got %v", unsupported)
+	}
+}
+
+func TestTranslatePostmanScript(t *testing.T) {
+	script := `pm.test("ok", function () {
+    pm.expect(pm.response.code).to.eql(200);
+});
+pm.environment.set("token", pm.response.json().token);
+pm.request.headers.upsert({ key: "X-Trace", value: pm.variables.get("traceId") });
+const key = pm.variables.get('process_env_API_KEY');
+pm.sendRequest("https://example.com");
+`
+	translated, untranslated := TranslatePostmanScript(script)
+
+	expected := []string{
+		`test("ok", function () {`,
+		`expect(res.getStatus()).to.eql(200);`,
+		`bru.setEnvVar("token", res.getBody().token);`,
+		`req.setHeader("X-Trace", bru.getVar("traceId"));`,
+		`const key = bru.getProcessEnv("API_KEY");`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(translated, e) {
+			t.Errorf("Expected translated script to contain %q, got:\n%s", e, translated)
+		}
+	}
+	if len(untranslated) != 1 || untranslated[0] != `pm.sendRequest("https://example.com")` {
+		t.Errorf("Expected pm.sendRequest to be reported, got %v", untranslated)
+	}
+}
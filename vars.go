@@ -0,0 +1,230 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Variable scopes, from the lowest to the highest precedence
+const (
+	ScopeCollection  = "collection"
+	ScopeEnvironment = "environment"
+	ScopeFolder      = "folder"
+	ScopeRequest     = "request"
+	ScopeOverride    = "replace"
+)
+
+var scopeRank = map[string]int{
+	ScopeCollection:  0,
+	ScopeEnvironment: 1,
+	ScopeFolder:      2,
+	ScopeRequest:     3,
+	ScopeOverride:    4,
+}
+
+// VariableResolver gives the effective value of the variables of a request,
+// following Bruno's precedence: request vars win over folder vars (the
+// deepest folder first), which win over the environment, which wins over
+// collection.bru. Values passed with -replace win over everything. Runtime
+// variables, set by scripts or vars:post-response, are only known when the
+// requests run and are never resolved.
+//
+// A resolver is immutable: With returns a new resolver for a nested scope.
+type VariableResolver struct {
+	layers  []varLayer // lowest precedence first
+	removed map[string]bool
+}
+
+type varLayer struct {
+	scope string
+	vars  []KeyValue
+}
+
+// NewVariableResolver creates the resolver of the collection root. Removed
+// variables are left out of every scope.
+func NewVariableResolver(collection []KeyValue, environment map[string]string, overrides map[string]string, removed []string) *VariableResolver {
+	r := &VariableResolver{removed: make(map[string]bool)}
+	for _, name := range removed {
+		r.removed[name] = true
+	}
+	return r.With(ScopeCollection, collection).
+		With(ScopeEnvironment, sortedKeyValues(environment)).
+		With(ScopeOverride, sortedKeyValues(overrides))
+}
+
+// With returns a resolver with the enabled vars of a scope added. A scope
+// added later wins over the scopes of the same kind already present.
+func (r *VariableResolver) With(scope string, vars []KeyValue) *VariableResolver {
+	layer := varLayer{scope: scope}
+	for _, v := range vars {
+		if v.Enabled && !isDisabledVariableKey(v.Key) && !r.removed[v.Key] {
+			layer.vars = append(layer.vars, v)
+		}
+	}
+	if len(layer.vars) == 0 {
+		return r
+	}
+
+	nested := &VariableResolver{removed: r.removed}
+	nested.layers = append(nested.layers, r.layers...)
+	nested.layers = append(nested.layers, layer)
+	sort.SliceStable(nested.layers, func(i, j int) bool {
+		return scopeRank[nested.layers[i].scope] < scopeRank[nested.layers[j].scope]
+	})
+	return nested
+}
+
+// ForRequest returns the resolver of a request, with its vars:pre-request
+func (r *VariableResolver) ForRequest(bru *BruFile) *VariableResolver {
+	return r.With(ScopeRequest, preRequestVars(bru))
+}
+
+// Lookup returns the value of a variable as defined, without resolving the
+// variables it references, and the scope it comes from
+func (r *VariableResolver) Lookup(name string) (value string, scope string, ok bool) {
+	for i := len(r.layers) - 1; i >= 0; i-- {
+		for _, v := range r.layers[i].vars {
+			if v.Key == name {
+				return v.Value, r.layers[i].scope, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// Resolve returns the effective value of a variable, with the variables it
+// references resolved, and the scope it comes from
+func (r *VariableResolver) Resolve(name string) (value string, scope string, ok bool) {
+	value, scope, ok = r.Lookup(name)
+	if !ok {
+		return "", "", false
+	}
+	in := newInliner(r.Values())
+	return in.resolve(value, []string{name}), scope, true
+}
+
+// Values returns the effective (unresolved) value of every variable
+func (r *VariableResolver) Values() map[string]string {
+	values := make(map[string]string)
+	for _, layer := range r.layers {
+		for _, v := range layer.vars {
+			values[v.Key] = v.Value
+		}
+	}
+	return values
+}
+
+// Variables returns the effective variables in the order they are first
+// defined, from the lowest scope up
+func (r *VariableResolver) Variables() []KeyValue {
+	values := r.Values()
+	seen := make(map[string]bool)
+	var vars []KeyValue
+	for _, layer := range r.layers {
+		for _, v := range layer.vars {
+			if !seen[v.Key] {
+				seen[v.Key] = true
+				vars = append(vars, KeyValue{Key: v.Key, Value: values[v.Key], Enabled: true})
+			}
+		}
+	}
+	return vars
+}
+
+// WithFolders returns the resolver of a directory of the collection, with the
+// vars of every folder.bru from the collection root down to dir
+func (r *VariableResolver) WithFolders(root string, dir string) *VariableResolver {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return r
+	}
+	path := root
+	for _, segment := range strings.Split(rel, string(filepath.Separator)) {
+		path = filepath.Join(path, segment)
+		if bru, err := ParseBruFile(filepath.Join(path, "folder.bru")); err == nil {
+			r = r.With(ScopeFolder, preRequestVars(bru))
+		}
+	}
+	return r
+}
+
+func sortedKeyValues(values map[string]string) []KeyValue {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	vars := make([]KeyValue, len(keys))
+	for i, k := range keys {
+		vars[i] = KeyValue{Key: k, Value: values[k], Enabled: true}
+	}
+	return vars
+}
+
+// runVars implements "bru-ship vars": print the effective value of the
+// variables of a request, and the scope each one comes from
+func runVars(args []string) int {
+	fs := flag.NewFlagSet("vars", flag.ExitOnError)
+	var input string
+	var env string
+	var request string
+	var name string
+	var replaces arrayFlags
+	fs.StringVar(&input, "input", ".", "Root directory of Bruno collection")
+	fs.StringVar(&env, "env", "", "Environment name to load variables from (e.g., Production)")
+	fs.StringVar(&request, "request", "", "Path of the request .bru file, relative to -input (default: the collection root)")
+	fs.StringVar(&name, "var", "", "Only print this variable")
+	fs.Var(&replaces, "replace", "Variable replacement in format key=value (can be repeated)")
+	fs.Parse(args)
+
+	var collectionVars []KeyValue
+	if bru, err := ParseBruFile(filepath.Join(input, "collection.bru")); err == nil {
+		collectionVars = preRequestVars(bru)
+	}
+
+	var envVars map[string]string
+	if env != "" {
+		envPath := filepath.Join(input, "environments", env+".bru")
+		bruEnv, err := ParseEnvironment(envPath)
+		if err != nil {
+			fmt.Printf("Error: Could not load environment file %s: %v\n", envPath, err)
+			return 1
+		}
+		envVars = EnvironmentVars(bruEnv, loadDotEnv(input), false)
+	}
+
+	overrides := make(map[string]string)
+	for _, r := range replaces {
+		if key, value, ok := strings.Cut(r, "="); ok {
+			overrides[key] = value
+		}
+	}
+
+	resolver := NewVariableResolver(collectionVars, envVars, overrides, nil)
+	if request != "" {
+		path := filepath.Join(input, request)
+		bru, err := ParseBruFile(path)
+		if err != nil {
+			fmt.Printf("Error: Could not parse %s: %v\n", path, err)
+			return 1
+		}
+		resolver = resolver.WithFolders(input, filepath.Dir(path)).ForRequest(bru)
+	}
+
+	vars := resolver.Variables()
+	if name != "" {
+		if _, _, ok := resolver.Lookup(name); !ok {
+			fmt.Printf("%s is not defined\n", name)
+			return 1
+		}
+		vars = []KeyValue{{Key: name}}
+	}
+	for _, v := range vars {
+		value, scope, _ := resolver.Resolve(v.Key)
+		fmt.Printf("%s = %s (%s)\n", v.Key, value, scope)
+	}
+	return 0
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVariableResolver_Precedence(t *testing.T) {
+	collection := []KeyValue{
+		{Key: "host", Value: "collection", Enabled: true},
+		{Key: "baseUrl", Value: "https://{{host}}", Enabled: true},
+		{Key: "secret", Value: "removed", Enabled: true},
+		{Key: "off", Value: "disabled", Enabled: false},
+	}
+	env := map[string]string{"host": "env", "user": "env"}
+	overrides := map[string]string{"user": "replace"}
+	resolver := NewVariableResolver(collection, env, overrides, []string{"secret"}).
+		With(ScopeFolder, []KeyValue{{Key: "host", Value: "folder", Enabled: true}, {Key: "page", Value: "1", Enabled: true}}).
+		With(ScopeFolder, []KeyValue{{Key: "page", Value: "2", Enabled: true}})
+
+	bru := &BruFile{
+		Vars:             []KeyValue{{Key: "page", Value: "3", Enabled: true}, {Key: "user", Value: "request", Enabled: true}, {Key: "id", Value: "res.body.id", Enabled: true}},
+		PostResponseVars: []KeyValue{{Key: "id", Value: "res.body.id", Enabled: true}},
+	}
+	request := resolver.ForRequest(bru)
+
+	tests := []struct {
+		resolver *VariableResolver
+		name     string
+		value    string
+		scope    string
+	}{
+		{resolver, "host", "folder", ScopeFolder},
+		{resolver, "baseUrl", "https://folder", ScopeCollection},
+		{resolver, "page", "2", ScopeFolder},
+		{request, "page", "3", ScopeRequest},
+		{request, "user", "replace", ScopeOverride},
+	}
+	for _, tt := range tests {
+		value, scope, ok := tt.resolver.Resolve(tt.name)
+		if !ok || value != tt.value || scope != tt.scope {
+			t.Errorf("%s: expected %q from %s, got %q from %s", tt.name, tt.value, tt.scope, value, scope)
+		}
+	}
+
+	for _, name := range []string{"secret", "off", "id"} {
+		if _, _, ok := request.Lookup(name); ok {
+			t.Errorf("Expected %s to be undefined", name)
+		}
+	}
+
+	var keys []string
+	for _, v := range NewVariableResolver(collection, env, overrides, []string{"secret"}).Variables() {
+		keys = append(keys, v.Key+"="+v.Value)
+	}
+	if got := strings.Join(keys, ","); got != "host=env,baseUrl=https://{{host}},user=replace" {
+		t.Errorf("Unexpected variables: %s", got)
+	}
+}
+
+func TestWalkAndConvert_FolderVars(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"collection.bru":             "vars:pre-request {\n  baseUrl: https://api.example.com\n  version: v1\n}\n",
+		"Users/folder.bru":           "meta {\n  name: Users\n}\n\nvars:pre-request {\n  version: v2\n  resource: users\n}\n",
+		"Users/Admin/folder.bru":     "meta {\n  name: Admin\n}\n\nvars:pre-request {\n  resource: admins\n}\n",
+		"Users/Admin/List.bru":       "meta {\n  name: List Admins\n}\n\nget {\n  url: {{baseUrl}}/{{version}}/{{resource}}\n}\n",
+		"Users/List.bru":             "meta {\n  name: List Users\n}\n\nget {\n  url: {{baseUrl}}/{{version}}/{{resource}}\n}\n",
+		"Orders/List.bru":            "meta {\n  name: List Orders\n}\n\nget {\n  url: {{baseUrl}}/{{version}}/orders\n}\n",
+		"Orders/Archived/folder.bru": "meta {\n  name: Archived\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collection, err := WalkAndConvert(Config{Input: tmpDir, InlineVars: true, Replace: map[string]string{"baseUrl": "https://staging.example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	urls := make(map[string]string)
+	var walk func(items []Item)
+	walk = func(items []Item) {
+		for _, item := range items {
+			if item.Request != nil {
+				urls[item.Name] = item.Request.Url.Raw
+			}
+			walk(item.Item)
+		}
+	}
+	walk(collection.Item)
+	expected := map[string]string{
+		"List Admins": "https://staging.example.com/v2/admins",
+		"List Users":  "https://staging.example.com/v2/users",
+		"List Orders": "https://staging.example.com/v1/orders",
+	}
+	for name, url := range expected {
+		if urls[name] != url {
+			t.Errorf("%s: expected %s, got %s", name, url, urls[name])
+		}
+	}
+
+	// The collection variables hold the effective values at the root
+	variables := make(map[string]string)
+	for _, v := range collection.Variable {
+		variables[v.Key] = v.Value
+	}
+	if variables["baseUrl"] != "https://staging.example.com" || variables["version"] != "v1" || len(variables) != 2 {
+		t.Errorf("Unexpected collection variables: %v", collection.Variable)
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// bodyBlocks maps the request body mode to the block holding a text body
+var bodyBlocks = map[string]string{
+	"json":    "body:json",
+	"text":    "body:text",
+	"xml":     "body:xml",
+	"sparql":  "body:sparql",
+	"graphql": "body:graphql",
+}
+
+// textBodyOrder is the order Bruno writes the text body blocks
+var textBodyOrder = []string{"body:json", "body:text", "body:xml", "body:sparql", "body:graphql"}
+
+// authKeyOrder is the order Bruno writes the keys of each auth:<mode> block
+var authKeyOrder = map[string][]string{
+	"bearer": {"token"},
+	"basic":  {"username", "password"},
+	"digest": {"username", "password"},
+	"ntlm":   {"username", "password", "domain"},
+	"wsse":   {"username", "password"},
+	"apikey": {"key", "value", "placement"},
+	"awsv4":  {"accessKeyId", "secretAccessKey", "sessionToken", "service", "region", "profileName"},
+	"oauth2": {"grant_type", "callback_url", "authorization_url", "access_token_url", "refresh_token_url", "client_id", "client_secret", "scope", "state", "pkce", "username", "password", "credentials_placement", "credentials_id", "token_placement", "token_header_prefix", "auto_fetch_token", "auto_refresh_token"},
+}
+
+// WriteBruFile serializes a BruFile and writes it to path
+func WriteBruFile(path string, bru *BruFile) error {
+	return os.WriteFile(path, []byte(FormatBruFile(bru)), 0644)
+}
+
+// FormatBruFile serializes a BruFile to .bru text.
+// Request files get a method block, folder.bru and collection.bru
+// (no method) get an auth block instead.
+func FormatBruFile(bru *BruFile) string {
+	var blocks []string
+
+	// meta
+	meta := []KeyValue{}
+	if bru.Name != "" {
+		meta = append(meta, KeyValue{Key: "name", Value: bru.Name, Enabled: true})
+	}
+	if bru.Method != "" {
+		bruType := bru.Type
+		if bruType == "" {
+			bruType = "http"
+		}
+		meta = append(meta, KeyValue{Key: "type", Value: bruType, Enabled: true})
+	}
+	if bru.Seq > 0 {
+		meta = append(meta, KeyValue{Key: "seq", Value: fmt.Sprint(bru.Seq), Enabled: true})
+	}
+	if len(meta) > 0 || len(bru.Tags) > 0 {
+		block := formatDictBlock("meta", meta)
+		if len(bru.Tags) > 0 {
+			// tags: [ with one tag per line, before the closing brace
+			block = strings.TrimSuffix(block, "}\n") + "  tags: [\n" + indentText(strings.Join(bru.Tags, "\n"), "    ") + "  ]\n}\n"
+		}
+		blocks = append(blocks, block)
+	}
+
+	mode := bru.Auth["mode"]
+	if bru.Method != "" {
+		request := []KeyValue{{Key: "url", Value: bru.Url, Enabled: true}}
+		if bru.BodyMode != "" {
+			request = append(request, KeyValue{Key: "body", Value: bru.BodyMode, Enabled: true})
+		}
+		if mode != "" {
+			request = append(request, KeyValue{Key: "auth", Value: mode, Enabled: true})
+		}
+		blocks = append(blocks, formatDictBlock(strings.ToLower(bru.Method), request))
+	}
+
+	if len(bru.Query) > 0 {
+		blocks = append(blocks, formatDictBlock("params:query", bru.Query))
+	}
+	if len(bru.Params) > 0 {
+		blocks = append(blocks, formatDictBlock("params:path", bru.Params))
+	}
+	if len(bru.Headers) > 0 {
+		blocks = append(blocks, formatDictBlock("headers", bru.Headers))
+	}
+	// collection.bru and folder.bru hold the auth mode in its own block,
+	// written after the headers
+	if bru.Method == "" && mode != "" {
+		blocks = append(blocks, formatDictBlock("auth", []KeyValue{{Key: "mode", Value: mode, Enabled: true}}))
+	}
+	if authBlock := formatAuthBlock(bru.Auth); authBlock != "" {
+		blocks = append(blocks, authBlock)
+	}
+
+	// body, with the bodies of the other modes
+	bodies := make(map[string]string)
+	for block, text := range bru.OtherBodies {
+		bodies[block] = text
+	}
+	if bru.Body != "" {
+		block, ok := bodyBlocks[bru.BodyMode]
+		if !ok {
+			block = "body:text"
+			if trimmed := strings.TrimSpace(bru.Body); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+				block = "body:json"
+			}
+		}
+		bodies[block] = bru.Body
+	}
+	for _, block := range textBodyOrder {
+		if text, ok := bodies[block]; ok {
+			blocks = append(blocks, formatTextBlock(block, text))
+			delete(bodies, block)
+		}
+	}
+	for _, block := range sortedKeys(bodies) {
+		blocks = append(blocks, formatTextBlock(block, bodies[block]))
+	}
+	if bru.GraphqlVars != "" {
+		blocks = append(blocks, formatTextBlock("body:graphql:vars", bru.GraphqlVars))
+	}
+	if len(bru.FormUrlEncoded) > 0 {
+		blocks = append(blocks, formatDictBlock("body:form-urlencoded", bru.FormUrlEncoded))
+	}
+	if len(bru.MultipartForm) > 0 {
+		blocks = append(blocks, formatDictBlock("body:multipart-form", bru.MultipartForm))
+	}
+
+	// vars
+	if preRequest := preRequestVars(bru); len(preRequest) > 0 {
+		blocks = append(blocks, formatDictBlock("vars:pre-request", preRequest))
+	}
+	if len(bru.PostResponseVars) > 0 {
+		blocks = append(blocks, formatDictBlock("vars:post-response", bru.PostResponseVars))
+	}
+
+	if len(bru.Asserts) > 0 {
+		blocks = append(blocks, formatDictBlock("assert", bru.Asserts))
+	}
+	if bru.PreRequestScript != "" {
+		blocks = append(blocks, formatTextBlock("script:pre-request", bru.PreRequestScript))
+	}
+	if bru.PostResponseScript != "" {
+		blocks = append(blocks, formatTextBlock("script:post-response", bru.PostResponseScript))
+	}
+	if bru.Tests != "" {
+		blocks = append(blocks, formatTextBlock("tests", bru.Tests))
+	}
+	if bru.Docs != "" {
+		blocks = append(blocks, formatTextBlock("docs", bru.Docs))
+	}
+	if len(bru.Settings) > 0 {
+		blocks = append(blocks, formatDictBlock("settings", bru.Settings))
+	}
+	for _, ex := range bru.Examples {
+		blocks = append(blocks, formatExample(ex))
+	}
+
+	return strings.Join(blocks, "\n")
+}
+
+// FormatEnvironment serializes a Bruno environment to .bru text
+func FormatEnvironment(env *BruEnvironment) string {
+	var sb strings.Builder
+	sb.WriteString(formatDictBlock("vars", env.Vars))
+	if len(env.Secrets) > 0 {
+		sb.WriteString("vars:secret [\n")
+		for i, secret := range env.Secrets {
+			sb.WriteString("  ")
+			if !secret.Enabled {
+				sb.WriteString("~")
+			}
+			sb.WriteString(secret.Key)
+			if i < len(env.Secrets)-1 {
+				sb.WriteString(",")
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("]\n")
+	}
+	return sb.String()
+}
+
+// preRequestVars returns the entries of bru.Vars that don't come from vars:post-response
+func preRequestVars(bru *BruFile) []KeyValue {
+	postResponse := make(map[KeyValue]int)
+	for _, v := range bru.PostResponseVars {
+		postResponse[v]++
+	}
+	var vars []KeyValue
+	for _, v := range bru.Vars {
+		if postResponse[v] > 0 {
+			postResponse[v]--
+			continue
+		}
+		vars = append(vars, v)
+	}
+	return vars
+}
+
+// formatDictBlock writes a "key: value" block, disabled entries get the "~" prefix.
+// Values spanning several lines are written as triple-quoted multiline strings.
+func formatDictBlock(name string, entries []KeyValue) string {
+	var sb strings.Builder
+	sb.WriteString(name + " {\n")
+	for _, e := range entries {
+		sb.WriteString("  ")
+		if !e.Enabled {
+			sb.WriteString("~")
+		}
+		if strings.Contains(e.Value, "\n") {
+			sb.WriteString(e.Key + ": '''\n")
+			sb.WriteString(indentText(e.Value+"\n", "    "))
+			sb.WriteString("  '''\n")
+			continue
+		}
+		sb.WriteString(e.Key + ": " + e.Value + "\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// formatTextBlock writes a block whose content is free text, indented one level
+func formatTextBlock(name string, content string) string {
+	return name + " {\n" + indentText(content, "  ") + "}\n"
+}
+
+// formatAuthBlock writes the auth:<mode> block with the settings of the auth mode
+func formatAuthBlock(auth map[string]string) string {
+	mode := auth["mode"]
+	if mode == "" || mode == "none" || mode == "inherit" {
+		return ""
+	}
+
+	var entries []KeyValue
+	written := map[string]bool{"mode": true, "inherit": true}
+	for _, key := range authKeyOrder[mode] {
+		if value, ok := auth[key]; ok {
+			entries = append(entries, KeyValue{Key: key, Value: value, Enabled: true})
+			written[key] = true
+		}
+	}
+	var rest []string
+	for key := range auth {
+		if !written[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		entries = append(entries, KeyValue{Key: key, Value: auth[key], Enabled: true})
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+	return formatDictBlock("auth:"+mode, entries)
+}
+
+func formatExample(ex BruExample) string {
+	var sb strings.Builder
+	sb.WriteString("example {\n")
+	sb.WriteString("  name: " + ex.Name + "\n")
+
+	sb.WriteString("\n  request: {\n")
+	sb.WriteString("    url: " + ex.Request.Url + "\n")
+	sb.WriteString("    method: " + ex.Request.Method + "\n")
+	if ex.Request.BodyMode != "" {
+		sb.WriteString("    mode: " + ex.Request.BodyMode + "\n")
+	}
+	if len(ex.Request.Query) > 0 {
+		sb.WriteString(formatExampleDict("params:query", ex.Request.Query))
+	}
+	if len(ex.Request.Headers) > 0 {
+		sb.WriteString(formatExampleDict("headers", ex.Request.Headers))
+	}
+	if ex.Request.Body != "" {
+		mode := ex.Request.BodyMode
+		if mode == "" || mode == "none" {
+			mode = "json"
+		}
+		sb.WriteString("    body:" + mode + ": {\n")
+		sb.WriteString(indentText(ex.Request.Body, "      "))
+		sb.WriteString("    }\n")
+	}
+	sb.WriteString("  }\n")
+
+	sb.WriteString("\n  response: {\n")
+	if len(ex.Response.Headers) > 0 {
+		sb.WriteString(formatExampleDict("headers", ex.Response.Headers))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("    status: {\n")
+	sb.WriteString(fmt.Sprintf("      code: %d\n", ex.Response.Status))
+	if ex.Response.StatusText != "" {
+		sb.WriteString("      text: " + ex.Response.StatusText + "\n")
+	}
+	sb.WriteString("    }\n")
+	if ex.Response.Body != "" {
+		bodyType := "text"
+		if trimmed := strings.TrimSpace(ex.Response.Body); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			bodyType = "json"
+		}
+		sb.WriteString("\n    body: {\n")
+		sb.WriteString("      type: " + bodyType + "\n")
+		sb.WriteString("      content: '''\n")
+		sb.WriteString(indentText(ex.Response.Body, "        "))
+		sb.WriteString("      '''\n")
+		sb.WriteString("    }\n")
+	}
+	sb.WriteString("  }\n")
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// formatExampleDict writes a "name: {" block of an example request or response
+func formatExampleDict(name string, entries []KeyValue) string {
+	var sb strings.Builder
+	sb.WriteString("    " + name + ": {\n")
+	for _, e := range entries {
+		sb.WriteString("      ")
+		if !e.Enabled {
+			sb.WriteString("~")
+		}
+		sb.WriteString(e.Key + ": " + e.Value + "\n")
+	}
+	sb.WriteString("    }\n")
+	return sb.String()
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// indentText indents every non-empty line of text, ending it with a newline
+func indentText(text string, indent string) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
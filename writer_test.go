@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+func TestFormatBruFile_RoundTrip(t *testing.T) {
+	bru, err := ParseBruFile(filepath.Join("testdata", "create-user.bru"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "create-user.bru")
+	if err := WriteBruFile(path, bru); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseBruFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(bru, parsed) {
+		t.Errorf("Round trip mismatch\nwant: %+v\ngot:  %+v", bru, parsed)
+	}
+}
+
+func TestFormatBruFile_Folder(t *testing.T) {
+	bru := &BruFile{
+		Name: "Users",
+		Seq:  2,
+		Auth: map[string]string{"mode": "basic", "username": "admin", "password": "secret"},
+		Docs: "Users API\n",
+	}
+
+	expected := `meta {
+  name: Users
+  seq: 2
+}
+
+auth {
+  mode: basic
+}
+
+auth:basic {
+  username: admin
+  password: secret
+}
+
+docs {
+  Users API
+}
+`
+	if got := FormatBruFile(bru); got != expected {
+		t.Errorf("Unexpected folder.bru:\n%s", got)
+	}
+}
+
+func TestFormatEnvironment(t *testing.T) {
+	env := &BruEnvironment{
+		Name: "Production",
+		Vars: []KeyValue{
+			{Key: "baseUrl", Value: "https://api.example.com", Enabled: true},
+			{Key: "debug", Value: "true", Enabled: false},
+		},
+		Secrets: []KeyValue{{Key: "apiKey", Enabled: true}, {Key: "token", Enabled: false}},
+	}
+
+	path := filepath.Join(t.TempDir(), "Production.bru")
+	if err := os.WriteFile(path, []byte(FormatEnvironment(env)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseEnvironment(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(env, parsed) {
+		t.Errorf("Round trip mismatch\nwant: %+v\ngot:  %+v", env, parsed)
+	}
+	if !strings.Contains(FormatEnvironment(env), "  ~debug: true\n") {
+		t.Errorf("Expected disabled variable to keep the ~ prefix")
+	}
+}
+
+// The fixtures are written in canonical layout: formatting them must give the
+// same text back, and parsing the output must give the same BruFile
+func TestFormatBruFile_Fixtures(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*.bru"))
+	if err != nil || len(fixtures) == 0 {
+		t.Fatalf("Expected .bru fixtures, got %v %v", fixtures, err)
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			content, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bru, err := ParseBruFile(fixture)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			formatted := FormatBruFile(bru)
+			if formatted != string(content) {
+				t.Errorf("Fixture is not in canonical layout, got:\n%s", formatted)
+			}
+
+			path := filepath.Join(t.TempDir(), "out.bru")
+			if err := os.WriteFile(path, []byte(formatted), 0644); err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseBruFile(path)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(bru, parsed) {
+				t.Errorf("Round trip mismatch\nwant: %+v\ngot:  %+v", bru, parsed)
+			}
+		})
+	}
+}
+
+func TestFormatBruFile_MultilineValues(t *testing.T) {
+	bru := &BruFile{
+		Name:     "Multiline",
+		Method:   "POST",
+		Url:      "{{baseUrl}}/notes",
+		BodyMode: "formUrlEncoded",
+		Headers:  []KeyValue{{Key: "X-Note", Value: "one\n  two\n\nfour", Enabled: true}},
+		FormUrlEncoded: []KeyValue{
+			{Key: "text", Value: "a\nb\n", Enabled: false},
+		},
+		Vars: []KeyValue{},
+		Auth: map[string]string{},
+	}
+
+	formatted := FormatBruFile(bru)
+	expected := `headers {
+  X-Note: '''
+    one
+      two
+
+    four
+  '''
+}
+`
+	if !strings.Contains(formatted, expected) {
+		t.Errorf("Expected the multiline header block, got:\n%s", formatted)
+	}
+	if !strings.Contains(formatted, "  ~text: '''\n    a\n    b\n\n  '''\n") {
+		t.Errorf("Expected the disabled multiline form field, got:\n%s", formatted)
+	}
+
+	path := filepath.Join(t.TempDir(), "multiline.bru")
+	if err := os.WriteFile(path, []byte(formatted), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseBruFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bru.Type = "http"
+	if !reflect.DeepEqual(bru, parsed) {
+		t.Errorf("Round trip mismatch\nwant: %+v\ngot:  %+v", bru, parsed)
+	}
+}
+
+// randomBru generates BruFiles in the shape ParseBruFile returns them
+type randomBru struct {
+	*BruFile
+}
+
+func (randomBru) Generate(r *rand.Rand, size int) reflect.Value {
+	words := []string{"alpha", "beta", "{{var}}", "x-1", "a b", "[1, 2]", "\"q\"", "res.status", "@file(a.png)", "}", "{"}
+	word := func() string { return words[r.Intn(len(words))] }
+	key := func() string { return fmt.Sprintf("key%d-%s", r.Intn(100), []string{"a", "b", "c"}[r.Intn(3)]) }
+	text := func(lines int) string {
+		var sb strings.Builder
+		for i := 0; i < lines; i++ {
+			if r.Intn(5) > 0 {
+				sb.WriteString(strings.Repeat(" ", 2*r.Intn(3)) + word() + " " + word())
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString(word() + "\n")
+		return sb.String()
+	}
+	value := func() string {
+		switch r.Intn(6) {
+		case 0:
+			return ""
+		case 1:
+			return strings.TrimSuffix(text(1+r.Intn(3)), "\n")
+		case 2:
+			return text(r.Intn(3))
+		default:
+			return word() + " " + word()
+		}
+	}
+	entries := func() []KeyValue {
+		var list []KeyValue
+		for i := r.Intn(size%5 + 1); i > 0; i-- {
+			list = append(list, KeyValue{Key: key(), Value: value(), Enabled: r.Intn(4) > 0})
+		}
+		return list
+	}
+
+	bru := &BruFile{
+		Name:    fmt.Sprintf("Request %d", r.Intn(1000)),
+		Type:    "http",
+		Seq:     1 + r.Intn(50),
+		Method:  []string{"GET", "POST", "PUT", "PATCH", "DELETE"}[r.Intn(5)],
+		Url:     "{{baseUrl}}/" + key(),
+		Headers: []KeyValue{},
+		Vars:    []KeyValue{},
+		Auth:    map[string]string{},
+		Query:   entries(),
+		Params:  entries(),
+		Asserts: entries(),
+	}
+	bru.Headers = append(bru.Headers, entries()...)
+	bru.Vars = append(bru.Vars, entries()...)
+	bru.PostResponseVars = entries()
+	bru.Vars = append(bru.Vars, bru.PostResponseVars...)
+
+	switch r.Intn(4) {
+	case 0:
+		bru.BodyMode = "json"
+		bru.Body = text(r.Intn(4))
+	case 1:
+		bru.BodyMode = "formUrlEncoded"
+		bru.FormUrlEncoded = entries()
+	case 2:
+		bru.BodyMode = "multipartForm"
+		bru.MultipartForm = entries()
+	default:
+		bru.BodyMode = "none"
+	}
+
+	switch r.Intn(3) {
+	case 0:
+		bru.Auth = map[string]string{"mode": "bearer", "token": value()}
+	case 1:
+		bru.Auth = map[string]string{"mode": "basic", "username": word(), "password": value()}
+	default:
+		bru.Auth = map[string]string{"mode": "inherit"}
+	}
+
+	if r.Intn(2) == 0 {
+		bru.PreRequestScript = text(r.Intn(4))
+	}
+	if r.Intn(2) == 0 {
+		bru.PostResponseScript = text(r.Intn(4))
+	}
+	if r.Intn(2) == 0 {
+		bru.Tests = text(r.Intn(4))
+	}
+	if r.Intn(2) == 0 {
+		bru.Docs = text(r.Intn(4))
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		bru.Tags = append(bru.Tags, key())
+	}
+	if r.Intn(2) == 0 {
+		bru.Settings = []KeyValue{{Key: "encodeUrl", Value: []string{"true", "false"}[r.Intn(2)], Enabled: true}}
+		if r.Intn(2) == 0 {
+			bru.Settings = append(bru.Settings, KeyValue{Key: "timeout", Value: fmt.Sprint(r.Intn(5000)), Enabled: true})
+		}
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		ex := BruExample{
+			Name:    fmt.Sprintf("Example %d", i),
+			Request: BruRequest{Url: bru.Url, Method: strings.ToLower(bru.Method)},
+			Response: BruResponse{
+				Status:     200 + r.Intn(300),
+				StatusText: "Status",
+				Body:       text(r.Intn(3)),
+			},
+		}
+		if r.Intn(2) == 0 {
+			ex.Request.BodyMode = []string{"json", "text", "xml"}[r.Intn(3)]
+			ex.Request.Body = text(r.Intn(3))
+		}
+		if r.Intn(2) == 0 {
+			ex.Response.Headers = []KeyValue{{Key: "Content-Type", Value: "application/json", Enabled: true}}
+		}
+		bru.Examples = append(bru.Examples, ex)
+	}
+	return reflect.ValueOf(randomBru{bru})
+}
+
+// parse(write(x)) == x for any BruFile the parser can return
+func TestFormatBruFile_Property(t *testing.T) {
+	dir := t.TempDir()
+	roundTrip := func(x randomBru) bool {
+		path := filepath.Join(dir, "random.bru")
+		if err := WriteBruFile(path, x.BruFile); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseBruFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(x.BruFile, parsed) {
+			t.Logf("Round trip mismatch for:\n%s\nwant: %+v\ngot:  %+v", FormatBruFile(x.BruFile), x.BruFile, parsed)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 300}); err != nil {
+		t.Error(err)
+	}
+}